@@ -0,0 +1,32 @@
+package goteletracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// SetBaggage returns a copy of ctx whose baggage holds the value under the key, replacing
+// any previous value, e.g. to propagate a tenant ID to downstream services. The value may
+// hold any characters; it is percent-encoded when propagated. The ctx is returned unchanged
+// when the key is empty, the key or value is not valid UTF-8, or the baggage would exceed
+// its size limits.
+func SetBaggage(ctx context.Context, key, value string) context.Context {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// GetBaggage returns the decoded baggage value stored under the key in ctx, or an empty
+// string when there is none
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
@@ -0,0 +1,52 @@
+package goteletracer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// TestBaggage tests setting baggage, propagating it percent-encoded and reading it back
+func TestBaggage(t *testing.T) {
+	provider, _ := newTestProvider(t, &Config{
+		ServiceName:   "test-service",
+		DisableGlobal: true,
+	})
+
+	ctx := SetBaggage(context.Background(), "tenant.id", "first")
+	ctx = SetBaggage(ctx, "tenant.id", "acme corp/ü")
+	ctx = SetBaggage(ctx, "region", "eu-west-1")
+
+	if value := GetBaggage(ctx, "tenant.id"); value != "acme corp/ü" {
+		t.Errorf("expected the replaced value, got %q", value)
+	}
+
+	carrier := propagation.MapCarrier{}
+	provider.Inject(ctx, carrier)
+
+	header := carrier.Get("baggage")
+	if !strings.Contains(header, "tenant.id=acme%20corp/%C3%BC") {
+		t.Errorf("expected a percent-encoded value in the baggage header, got %q", header)
+	}
+
+	extracted := provider.Extract(context.Background(), carrier)
+	for key, expected := range map[string]string{"tenant.id": "acme corp/ü", "region": "eu-west-1"} {
+		if value := GetBaggage(extracted, key); value != expected {
+			t.Errorf("expected %s %q after propagation, got %q", key, expected, value)
+		}
+	}
+}
+
+// TestBaggageInvalidKey tests that an empty key leaves the context unchanged
+func TestBaggageInvalidKey(t *testing.T) {
+	ctx := SetBaggage(context.Background(), "tenant.id", "acme")
+
+	if got := SetBaggage(ctx, "", "other"); got != ctx {
+		t.Error("expected the context to be returned unchanged")
+	}
+	if value := GetBaggage(ctx, "missing"); value != "" {
+		t.Errorf("expected no value, got %q", value)
+	}
+}
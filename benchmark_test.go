@@ -0,0 +1,108 @@
+package goteletracer
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// benchmarkConfig returns a valid config used across benchmarks
+func benchmarkConfig() *Config {
+	return &Config{
+		ServiceName:         "benchmark-service",
+		ExporterGRPCAddress: "localhost:4317",
+	}
+}
+
+// BenchmarkNewTracerProvider measures provider construction with an in-memory exporter
+func BenchmarkNewTracerProvider(b *testing.B) {
+	cfg := benchmarkConfig()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		provider, err := newTracerProvider(ctx, cfg, tracetest.NewInMemoryExporter())
+		if err != nil {
+			b.Fatalf("failed to create provider: %v", err)
+		}
+
+		b.StopTimer()
+		_ = provider.Shutdown(ctx)
+		b.StartTimer()
+	}
+}
+
+// BenchmarkStartSpan measures span creation and ending on a recording tracer
+func BenchmarkStartSpan(b *testing.B) {
+	ctx := context.Background()
+	provider, err := newTracerProvider(ctx, benchmarkConfig(), tracetest.NewInMemoryExporter())
+	if err != nil {
+		b.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(ctx)
+
+	tracer := provider.Tracer()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, span := tracer.Start(ctx, "benchmark-span")
+		span.End()
+	}
+}
+
+// BenchmarkNoopStartSpan measures the noop fast path returned by NewTracer(nil)
+func BenchmarkNoopStartSpan(b *testing.B) {
+	ctx := context.Background()
+	tracer := NewTracer(nil)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, span := tracer.Start(ctx, "benchmark-span")
+		span.End()
+	}
+}
+
+// BenchmarkDeepTraceSampling compares sampling cost on deep span trees with and
+// without reusing the root decision from the context
+func BenchmarkDeepTraceSampling(b *testing.B) {
+	const depth = 16
+
+	for _, inherit := range []bool{false, true} {
+		name := "uncached"
+		if inherit {
+			name = "cached"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			cfg := benchmarkConfig()
+			cfg.SpanKindSamplingRatios = map[trace.SpanKind]float64{
+				trace.SpanKindServer:   1,
+				trace.SpanKindInternal: 1,
+			}
+			cfg.InheritParentSampling = inherit
+
+			ctx := context.Background()
+			provider, err := newTracerProvider(ctx, cfg, tracetest.NewNoopExporter())
+			if err != nil {
+				b.Fatalf("failed to create provider: %v", err)
+			}
+			defer provider.Shutdown(ctx)
+
+			tracer := provider.Tracer()
+			spans := make([]trace.Span, depth)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				spanCtx := ctx
+				for i := range spans {
+					spanCtx, spans[i] = tracer.Start(spanCtx, "benchmark-span", trace.WithSpanKind(trace.SpanKindInternal))
+				}
+				for i := len(spans) - 1; i >= 0; i-- {
+					spans[i].End()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	collector_trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+)
+
+// testCollector is an in-process OTLP GRPC trace collector recording export requests
+type testCollector struct {
+	collector_trace.UnimplementedTraceServiceServer
+
+	address string
+
+	mu       sync.Mutex
+	spans    int
+	metadata []metadata.MD
+}
+
+// newTestCollector starts a testCollector on a random local port, stopped on test cleanup
+func newTestCollector(t *testing.T) *testCollector {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	return serveTestCollector(t, listener, listener.Addr().String())
+}
+
+// serveTestCollector serves a testCollector reachable at address on the listener
+func serveTestCollector(t *testing.T, listener net.Listener, address string) *testCollector {
+	t.Helper()
+
+	collector := &testCollector{address: address}
+	server := grpc.NewServer()
+	collector_trace.RegisterTraceServiceServer(server, collector)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return collector
+}
+
+// Export records the request metadata and the number of exported spans
+func (c *testCollector) Export(ctx context.Context, request *collector_trace.ExportTraceServiceRequest) (*collector_trace.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metadata = append(c.metadata, md)
+	for _, resourceSpans := range request.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			c.spans += len(scopeSpans.Spans)
+		}
+	}
+
+	return &collector_trace.ExportTraceServiceResponse{}, nil
+}
+
+// exportedSpans returns the number of spans received so far
+func (c *testCollector) exportedSpans() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.spans
+}
+
+// requests returns the metadata of the export requests received so far
+func (c *testCollector) requests() []metadata.MD {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]metadata.MD(nil), c.metadata...)
+}
+
+// TestExportHeaders tests that configured headers are sent with every export request
+func TestExportHeaders(t *testing.T) {
+	collector := newTestCollector(t)
+
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: collector.address,
+		Headers:             map[string]string{"x-api-key": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	for range 2 {
+		_, span := provider.Tracer().Start(context.Background(), "operation")
+		span.End()
+
+		if err := provider.ForceFlush(context.Background()); err != nil {
+			t.Fatalf("expected no flush error, got %v", err)
+		}
+	}
+
+	requests := collector.requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 export requests, got %d", len(requests))
+	}
+	for i, md := range requests {
+		if values := md.Get("x-api-key"); len(values) != 1 || values[0] != "secret" {
+			t.Errorf("request %d: expected x-api-key header, got %v", i, md)
+		}
+	}
+}
+
+// TestUnixSocketExporter tests exporting to a collector listening on a unix domain socket
+func TestUnixSocketExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otel.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	collector := serveTestCollector(t, listener, "unix://"+path)
+
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: collector.address,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if spans := collector.exportedSpans(); spans != 1 {
+		t.Errorf("expected 1 span over the unix socket, got %d", spans)
+	}
+}
+
+// TestExporterAddresses tests that spans are exported to every collector and that
+// Shutdown flushes and closes each of them
+func TestExporterAddresses(t *testing.T) {
+	primary := newTestCollector(t)
+	secondary := newTestCollector(t)
+
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: primary.address,
+		ExporterAddresses:   []string{secondary.address},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "flushed")
+	span.End()
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	for name, collector := range map[string]*testCollector{"primary": primary, "secondary": secondary} {
+		if spans := collector.exportedSpans(); spans != 1 {
+			t.Errorf("%s: expected 1 span after flush, got %d", name, spans)
+		}
+	}
+
+	_, span = provider.Tracer().Start(context.Background(), "pending")
+	span.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+
+	for name, collector := range map[string]*testCollector{"primary": primary, "secondary": secondary} {
+		if spans := collector.exportedSpans(); spans != 2 {
+			t.Errorf("%s: expected the pending span to be flushed on shutdown, got %d spans", name, spans)
+		}
+	}
+
+	if stats := provider.Stats(); stats.Exported != 4 {
+		t.Errorf("expected Stats to count the spans of both collectors, got %d exported", stats.Exported)
+	}
+
+	if len(provider.extraConns) != 1 {
+		t.Fatalf("expected 1 additional connection, got %d", len(provider.extraConns))
+	}
+	if state := provider.extraConns[0].GetState(); state != connectivity.Shutdown {
+		t.Errorf("expected the additional connection to be closed, got %s", state)
+	}
+}
+
+// TestExporterAddressesDialFailure tests that a collector failing to be dialed leaves the globals untouched
+func TestExporterAddressesDialFailure(t *testing.T) {
+	errDial := errors.New("dial failed")
+	var calls int
+	original := newOTLPExporter
+	newOTLPExporter = func(ctx context.Context, opts ...otlptracegrpc.Option) (*otlptrace.Exporter, error) {
+		calls++
+		if calls == 2 {
+			return nil, errDial
+		}
+		return original(ctx, opts...)
+	}
+	t.Cleanup(func() { newOTLPExporter = original })
+
+	previous := noop.NewTracerProvider()
+	otel.SetTracerProvider(previous)
+
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: newTestCollector(t).address,
+		ExporterAddresses:   []string{newTestCollector(t).address},
+	})
+	if !errors.Is(err, errDial) {
+		t.Fatalf("expected the dial error, got %v", err)
+	}
+	if provider != nil {
+		t.Error("expected no provider on dial failure")
+	}
+	if otel.GetTracerProvider() != trace.TracerProvider(previous) {
+		t.Error("expected the global provider to be left untouched on dial failure")
+	}
+}
+
+// TestExporterAddressesHealthCheck tests that HealthCheck fails when an additional collector is unreachable
+func TestExporterAddressesHealthCheck(t *testing.T) {
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: newTestCollector(t).address,
+		ExporterAddresses:   []string{closedAddress(t)},
+		DisableGlobal:       true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := provider.HealthCheck(ctx); !errors.Is(err, ErrCollectorUnreachable) {
+		t.Errorf("expected %v, got %v", ErrCollectorUnreachable, err)
+	}
+}
+
+// TestExporterAddressesReconnect tests that Reconnect replaces the connections of every collector
+func TestExporterAddressesReconnect(t *testing.T) {
+	secondary := newTestCollector(t)
+
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: newTestCollector(t).address,
+		ExporterAddresses:   []string{secondary.address},
+		DisableGlobal:       true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	previousConns := provider.connections()
+	if err := provider.Reconnect(); err != nil {
+		t.Fatalf("expected no reconnect error, got %v", err)
+	}
+
+	for i, conn := range provider.connections() {
+		if conn == previousConns[i] {
+			t.Errorf("connection %d: expected a new GRPC connection after reconnect", i)
+		}
+		if state := previousConns[i].GetState(); state != connectivity.Shutdown {
+			t.Errorf("connection %d: expected previous connection to be shut down, got %s", i, state)
+		}
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "after reconnect")
+	span.End()
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if spans := secondary.exportedSpans(); spans != 1 {
+		t.Errorf("expected the secondary collector to receive the span, got %d", spans)
+	}
+}
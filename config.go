@@ -0,0 +1,172 @@
+package goteletracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedConfigFormat is returned by LoadConfig for files that are neither JSON nor YAML
+var ErrUnsupportedConfigFormat = errors.New("config file must be JSON (.json) or YAML (.yaml, .yml)")
+
+// LoadConfig reads a config from a JSON or YAML file, told apart by the .json, .yaml or .yml
+// extension, using the json field names of Config, e.g. "service_name" and
+// "exporter_grpc_address". Other extensions return ErrUnsupportedConfigFormat. Unknown
+// fields are rejected to catch typos.
+// Settings that cannot be expressed in a file, such as TLS, Logger or samplers, are left
+// unset. The config is not validated so that missing values can still be filled in,
+// e.g. with MergeEnv.
+func LoadConfig(path string) (*Config, error) {
+	extension := strings.ToLower(filepath.Ext(path))
+	if extension != ".json" && extension != ".yaml" && extension != ".yml" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedConfigFormat, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// YAML is decoded through the json field names by converting it to JSON first
+	if extension != ".json" {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// yamlToJSON converts a YAML document to JSON
+func yamlToJSON(data []byte) ([]byte, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonValue(document))
+}
+
+// jsonValue converts a decoded YAML value to one encoding/json can marshal, turning
+// mappings with non-string keys, e.g. span kinds, into objects keyed by the key's text
+func jsonValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = jsonValue(item)
+		}
+		return value
+	case map[any]any:
+		object := make(map[string]any, len(value))
+		for key, item := range value {
+			object[fmt.Sprint(key)] = jsonValue(item)
+		}
+		return object
+	case []any:
+		for i, item := range value {
+			value[i] = jsonValue(item)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// configFields has the fields of Config without its JSON methods
+type configFields Config
+
+// configJSON is the JSON representation of Config, encoding durations as strings
+// such as "30s" by shadowing the duration fields of the embedded config
+type configJSON struct {
+	*configFields
+	RetryInitialInterval *jsonDuration `json:"retry_initial_interval"`
+	RetryMaxInterval     *jsonDuration `json:"retry_max_interval"`
+	RetryMaxElapsedTime  *jsonDuration `json:"retry_max_elapsed_time"`
+	KeepAliveTime        *jsonDuration `json:"keep_alive_time"`
+	KeepAliveTimeout     *jsonDuration `json:"keep_alive_timeout"`
+	ConnectTimeout       *jsonDuration `json:"connect_timeout"`
+	ShutdownTimeout      *jsonDuration `json:"shutdown_timeout"`
+	MaxShutdownTimeout   *jsonDuration `json:"max_shutdown_timeout"`
+	FlushTimeout         *jsonDuration `json:"flush_timeout"`
+	BatchTimeout         *jsonDuration `json:"batch_timeout"`
+	ExportTimeout        *jsonDuration `json:"export_timeout"`
+	HeartbeatInterval    *jsonDuration `json:"heartbeat_interval"`
+}
+
+// newConfigJSON returns the JSON representation reading from and writing to cfg
+func newConfigJSON(cfg *Config) *configJSON {
+	return &configJSON{
+		configFields:         (*configFields)(cfg),
+		RetryInitialInterval: (*jsonDuration)(&cfg.RetryInitialInterval),
+		RetryMaxInterval:     (*jsonDuration)(&cfg.RetryMaxInterval),
+		RetryMaxElapsedTime:  (*jsonDuration)(&cfg.RetryMaxElapsedTime),
+		KeepAliveTime:        (*jsonDuration)(&cfg.KeepAliveTime),
+		KeepAliveTimeout:     (*jsonDuration)(&cfg.KeepAliveTimeout),
+		ConnectTimeout:       (*jsonDuration)(&cfg.ConnectTimeout),
+		ShutdownTimeout:      (*jsonDuration)(&cfg.ShutdownTimeout),
+		MaxShutdownTimeout:   (*jsonDuration)(&cfg.MaxShutdownTimeout),
+		FlushTimeout:         (*jsonDuration)(&cfg.FlushTimeout),
+		BatchTimeout:         (*jsonDuration)(&cfg.BatchTimeout),
+		ExportTimeout:        (*jsonDuration)(&cfg.ExportTimeout),
+		HeartbeatInterval:    (*jsonDuration)(&cfg.HeartbeatInterval),
+	}
+}
+
+// MarshalJSON encodes the config with its json field names and durations as strings
+// such as "30s". Fields that cannot be serialized, such as TLS, Logger or samplers, are omitted.
+func (cfg Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newConfigJSON(&cfg))
+}
+
+// UnmarshalJSON decodes a config encoded by MarshalJSON. Durations may be strings such as
+// "500ms" or numbers of nanoseconds. Unknown fields are rejected to catch typos, and fields
+// missing from the JSON keep their current value.
+func (cfg *Config) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(newConfigJSON(cfg))
+}
+
+// jsonDuration is a time.Duration encoded in JSON as a duration string
+type jsonDuration time.Duration
+
+// MarshalJSON encodes the duration as a string such as "1m30s"
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string or a number of nanoseconds
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case string:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		*d = jsonDuration(duration)
+	case float64:
+		*d = jsonDuration(value)
+	default:
+		return fmt.Errorf("invalid duration %s: must be a string such as \"30s\" or a number of nanoseconds", data)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package goteletracer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// writeConfigFile writes the content to a temporary JSON config file and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	return writeNamedConfigFile(t, "tracing.json", content)
+}
+
+// writeNamedConfigFile writes the content to a temporary config file with the given name
+// and returns its path
+func writeNamedConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	return path
+}
+
+// TestLoadConfigYAML tests reading YAML files through the json field names of Config
+func TestLoadConfigYAML(t *testing.T) {
+	const content = `
+service_name: orders
+exporter_grpc_address: collector:4317
+sampling_ratio: 0.25
+shutdown_timeout: 45s
+resource_attributes:
+  service.namespace: shop
+propagators: [tracecontext, b3]
+span_kind_sampling_ratios:
+  2: 1
+`
+
+	for _, name := range []string{"tracing.yaml", "tracing.yml", "TRACING.YAML"} {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeNamedConfigFile(t, name, content))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if cfg.ServiceName != "orders" || cfg.ExporterGRPCAddress != "collector:4317" {
+				t.Errorf("unexpected service or address: %q, %q", cfg.ServiceName, cfg.ExporterGRPCAddress)
+			}
+			if cfg.SamplingRatio != 0.25 || cfg.ShutdownTimeout != 45*time.Second {
+				t.Errorf("unexpected sampling ratio or shutdown timeout: %v, %v", cfg.SamplingRatio, cfg.ShutdownTimeout)
+			}
+			if cfg.ResourceAttributes["service.namespace"] != "shop" || len(cfg.Propagators) != 2 {
+				t.Errorf("expected the nested settings to be read, got %+v", cfg)
+			}
+			if ratio, ok := cfg.SpanKindSamplingRatios[trace.SpanKindServer]; !ok || ratio != 1 {
+				t.Errorf("expected a server span kind ratio of 1, got %v", cfg.SpanKindSamplingRatios)
+			}
+		})
+	}
+}
+
+// TestLoadConfigMergeEnv tests layering environment variables over a config file
+func TestLoadConfigMergeEnv(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"service_name": "orders",
+		"exporter_grpc_address": "collector:4317",
+		"sampling_ratio": 0.25,
+		"resource_attributes": {"service.namespace": "shop"},
+		"propagators": ["tracecontext", "b3"]
+	}`)
+
+	tests := []struct {
+		name            string
+		env             map[string]string
+		expectedService string
+		expectedAddress string
+		expectTLS       bool
+	}{
+		{
+			name:            "file only",
+			expectedService: "orders",
+			expectedAddress: "collector:4317",
+		},
+		{
+			name: "env overrides",
+			env: map[string]string{
+				EnvServiceName:            "orders-canary",
+				EnvTracesExporterEndpoint: "https://collector.example.com:4317",
+			},
+			expectedService: "orders-canary",
+			expectedAddress: "collector.example.com:4317",
+			expectTLS:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{EnvServiceName, EnvExporterEndpoint, EnvTracesExporterEndpoint} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if err := cfg.MergeEnv(); err != nil {
+				t.Fatalf("expected no merge error, got %v", err)
+			}
+
+			if cfg.ServiceName != tt.expectedService {
+				t.Errorf("expected service name %q, got %q", tt.expectedService, cfg.ServiceName)
+			}
+			if cfg.ExporterGRPCAddress != tt.expectedAddress {
+				t.Errorf("expected exporter address %q, got %q", tt.expectedAddress, cfg.ExporterGRPCAddress)
+			}
+			if (cfg.TLS != nil) != tt.expectTLS {
+				t.Errorf("expected TLS=%v, got %v", tt.expectTLS, cfg.TLS != nil)
+			}
+			if cfg.SamplingRatio != 0.25 || cfg.ResourceAttributes["service.namespace"] != "shop" || len(cfg.Propagators) != 2 {
+				t.Errorf("expected the file settings to be kept, got %+v", cfg)
+			}
+		})
+	}
+}
+
+// TestLoadConfigErrors tests unreadable files, unsupported formats, unknown fields and invalid merged configs
+func TestLoadConfigErrors(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a missing file error, got %v", err)
+	}
+
+	if _, err := LoadConfig(writeConfigFile(t, `{"service_nam": "orders"}`)); err == nil {
+		t.Error("expected an unknown field error")
+	}
+
+	if _, err := LoadConfig(writeNamedConfigFile(t, "tracing.yaml", "service_nam: orders\n")); err == nil {
+		t.Error("expected an unknown field error from a YAML file")
+	}
+
+	for _, name := range []string{"tracing.toml", "tracing"} {
+		if _, err := LoadConfig(filepath.Join(t.TempDir(), name)); !errors.Is(err, ErrUnsupportedConfigFormat) {
+			t.Errorf("expected ErrUnsupportedConfigFormat for %s, got %v", name, err)
+		}
+	}
+
+	for _, key := range []string{EnvServiceName, EnvExporterEndpoint, EnvTracesExporterEndpoint} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := LoadConfig(writeConfigFile(t, `{"service_name": "orders", "sampling_ratio": 2}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := cfg.MergeEnv(); !errors.Is(err, ErrEmptyExporterAddress) {
+		t.Errorf("expected the merged config to be validated, got %v", err)
+	}
+}
+
+// TestConfigJSONRoundTrip tests that a config survives JSON encoding with durations as strings
+func TestConfigJSONRoundTrip(t *testing.T) {
+	cfg := Config{
+		ServiceName:            "orders",
+		ExporterGRPCAddress:    "collector:4317",
+		ExporterAddresses:      []string{"backup:4317"},
+		ResourceAttributes:     map[string]string{"service.namespace": "shop"},
+		Headers:                map[string]string{"x-api-key": "secret"},
+		Compression:            CompressionGzip,
+		ShutdownTimeout:        90 * time.Second,
+		BatchTimeout:           500 * time.Millisecond,
+		HeartbeatInterval:      time.Minute,
+		MaxQueueSize:           4096,
+		SamplingRatio:          0.1,
+		SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindServer: 1},
+		Propagators:            []string{PropagatorTraceContext},
+		Logger:                 &captureLogger{},
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("expected no marshal error, got %v", err)
+	}
+
+	for _, expected := range []string{`"service_name":"orders"`, `"shutdown_timeout":"1m30s"`, `"batch_timeout":"500ms"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected JSON to contain %s, got %s", expected, data)
+		}
+	}
+	if strings.Contains(string(data), "Logger") || strings.Contains(string(data), "ServiceName") {
+		t.Errorf("expected only json field names, got %s", data)
+	}
+
+	var decoded Config
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected no unmarshal error, got %v", err)
+	}
+
+	cfg.Logger = nil
+	if !reflect.DeepEqual(decoded, cfg) {
+		t.Errorf("expected the decoded config to match\n got: %+v\nwant: %+v", decoded, cfg)
+	}
+}
+
+// TestConfigJSONDurations tests the accepted duration encodings
+func TestConfigJSONDurations(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{name: "duration string", json: `{"shutdown_timeout": "2m"}`, expected: 2 * time.Minute},
+		{name: "nanoseconds", json: `{"shutdown_timeout": 1000000000}`, expected: time.Second},
+		{name: "invalid string", json: `{"shutdown_timeout": "soon"}`, expectErr: true},
+		{name: "invalid type", json: `{"shutdown_timeout": true}`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := json.Unmarshal([]byte(tt.json), &cfg)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if cfg.ShutdownTimeout != tt.expected {
+				t.Errorf("expected shutdown timeout %v, got %v", tt.expected, cfg.ShutdownTimeout)
+			}
+		})
+	}
+}
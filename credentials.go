@@ -0,0 +1,77 @@
+package goteletracer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ErrInvalidCACert is returned when the CA certificate file holds no PEM encoded certificate
+var ErrInvalidCACert = errors.New("CA certificate file contains no valid certificate")
+
+// newTransportCredentials selects the exporter transport credentials: TLS with the config
+// from transportTLSConfig when there is one, and insecure credentials otherwise
+func newTransportCredentials(cfg *Config, options providerOptions) (credentials.TransportCredentials, error) {
+	tlsConfig, err := transportTLSConfig(cfg, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		return credentials.NewTLS(tlsConfig), nil
+	}
+
+	return insecure.NewCredentials(), nil
+}
+
+// transportTLSConfig returns the TLS config of the exporter connection: Config.TLS, trusting
+// the CA certificate file when WithCACertFile is used, with verification disabled when
+// InsecureSkipVerify is set. It returns nil when the connection is insecure.
+func transportTLSConfig(cfg *Config, options providerOptions) (*tls.Config, error) {
+	tlsConfig := cfg.TLS
+	if options.caCertFile != "" {
+		var err error
+		tlsConfig, err = caCertTLSConfig(cfg.TLS, options.caCertFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.InsecureSkipVerify {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
+
+// caCertTLSConfig returns a copy of base, or a new TLS config if base is nil,
+// trusting the CA certificates read from path
+func caCertTLSConfig(base *tls.Config, path string) (*tls.Config, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCACert, path)
+	}
+
+	tlsConfig := &tls.Config{}
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+	tlsConfig.RootCAs = pool
+
+	return tlsConfig, nil
+}
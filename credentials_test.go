@@ -0,0 +1,184 @@
+package goteletracer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCACert writes a self-signed PEM encoded CA certificate to a temporary file
+func writeCACert(t *testing.T) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+
+	return path
+}
+
+// TestNewTransportCredentials tests the credentials selected from the config and options
+func TestNewTransportCredentials(t *testing.T) {
+	caCertFile := writeCACert(t)
+	invalidCertFile := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidCertFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		config           *Config
+		opts             []Option
+		expectedProtocol string
+		expectedErr      error
+	}{
+		{
+			name:             "insecure by default",
+			config:           &Config{},
+			expectedProtocol: "insecure",
+		},
+		{
+			name:             "TLS config",
+			config:           &Config{TLS: &tls.Config{MinVersion: tls.VersionTLS12}},
+			expectedProtocol: "tls",
+		},
+		{
+			name:             "CA certificate file",
+			config:           &Config{},
+			opts:             []Option{WithCACertFile(caCertFile)},
+			expectedProtocol: "tls",
+		},
+		{
+			name:             "InsecureSkipVerify",
+			config:           &Config{InsecureSkipVerify: true},
+			expectedProtocol: "tls",
+		},
+		{
+			name:        "missing CA certificate file",
+			config:      &Config{},
+			opts:        []Option{WithCACertFile(filepath.Join(t.TempDir(), "missing.pem"))},
+			expectedErr: os.ErrNotExist,
+		},
+		{
+			name:        "invalid CA certificate file",
+			config:      &Config{},
+			opts:        []Option{WithCACertFile(invalidCertFile)},
+			expectedErr: ErrInvalidCACert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options providerOptions
+			for _, opt := range tt.opts {
+				opt(&options)
+			}
+
+			creds, err := newTransportCredentials(tt.config, options)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if protocol := creds.Info().SecurityProtocol; protocol != tt.expectedProtocol {
+				t.Errorf("expected %q credentials, got %q", tt.expectedProtocol, protocol)
+			}
+		})
+	}
+}
+
+// TestCACertTLSConfig tests that the CA pool is added to a copy of the base TLS config
+func TestCACertTLSConfig(t *testing.T) {
+	base := &tls.Config{ServerName: "collector.internal"}
+
+	tlsConfig, err := caCertTLSConfig(base, writeCACert(t))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if tlsConfig.RootCAs == nil || tlsConfig.ServerName != "collector.internal" {
+		t.Errorf("expected base settings with CA pool, got %+v", tlsConfig)
+	}
+	if base.RootCAs != nil {
+		t.Error("expected the base TLS config to be left untouched")
+	}
+}
+
+// TestInsecureSkipVerify tests that InsecureSkipVerify disables verification on a copy of the TLS config
+func TestInsecureSkipVerify(t *testing.T) {
+	base := &tls.Config{ServerName: "collector.internal"}
+
+	tests := []struct {
+		name   string
+		config *Config
+		opts   []Option
+	}{
+		{name: "without TLS config", config: &Config{InsecureSkipVerify: true}},
+		{name: "with TLS config", config: &Config{TLS: base, InsecureSkipVerify: true}},
+		{name: "with CA certificate file", config: &Config{TLS: base, InsecureSkipVerify: true}, opts: []Option{WithCACertFile(writeCACert(t))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var options providerOptions
+			for _, opt := range tt.opts {
+				opt(&options)
+			}
+
+			tlsConfig, err := transportTLSConfig(tt.config, options)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if tlsConfig == nil || !tlsConfig.InsecureSkipVerify {
+				t.Fatalf("expected verification to be disabled, got %+v", tlsConfig)
+			}
+			if tt.config.TLS != nil && tlsConfig.ServerName != "collector.internal" {
+				t.Errorf("expected the TLS config settings to be kept, got %+v", tlsConfig)
+			}
+			if len(tt.opts) > 0 && tlsConfig.RootCAs == nil {
+				t.Error("expected the CA pool to be kept")
+			}
+		})
+	}
+
+	if base.InsecureSkipVerify {
+		t.Error("expected the configured TLS config to be left untouched")
+	}
+}
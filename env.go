@@ -0,0 +1,87 @@
+package goteletracer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Standard OpenTelemetry environment variables read by NewConfigFromEnv
+const (
+	EnvServiceName            = "OTEL_SERVICE_NAME"
+	EnvExporterEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvTracesExporterEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// defaultOTLPGRPCPort is the port of endpoint URLs that do not specify one
+const defaultOTLPGRPCPort = "4317"
+
+// NewConfigFromEnv creates a config from the standard OpenTelemetry environment variables.
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT takes precedence over OTEL_EXPORTER_OTLP_ENDPOINT.
+// Endpoints may be URLs such as "https://collector:4317": the scheme is stripped,
+// https enables TLS and the port defaults to 4317. Missing or invalid values yield the usual validation errors.
+func NewConfigFromEnv() (*Config, error) {
+	cfg := &Config{}
+	if err := cfg.MergeEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// MergeEnv overrides the service name and exporter endpoint with the standard OpenTelemetry
+// environment variables that are set, e.g. on top of a config read with LoadConfig, and
+// validates the merged config. Endpoints are parsed like in NewConfigFromEnv; an https
+// endpoint enables TLS unless the config already has a TLS config.
+func (cfg *Config) MergeEnv() error {
+	if serviceName := os.Getenv(EnvServiceName); serviceName != "" {
+		cfg.ServiceName = serviceName
+	}
+
+	endpoint := os.Getenv(EnvTracesExporterEndpoint)
+	if endpoint == "" {
+		endpoint = os.Getenv(EnvExporterEndpoint)
+	}
+	if endpoint != "" {
+		address, tlsConfig := parseEndpoint(endpoint)
+		cfg.ExporterGRPCAddress = address
+		if cfg.TLS == nil {
+			cfg.TLS = tlsConfig
+		}
+	}
+
+	if err := ValidateConfig(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	return nil
+}
+
+// parseEndpoint converts an OTLP endpoint into a GRPC address, returning a TLS config
+// for https endpoints. URLs without a port use the OTLP GRPC port 4317. Endpoints without
+// an http or https scheme are returned as is.
+func parseEndpoint(endpoint string) (string, *tls.Config) {
+	endpoint = strings.TrimSpace(endpoint)
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return endpoint, nil
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint, nil
+	}
+
+	address := endpointURL.Host
+	if endpointURL.Port() == "" {
+		address = net.JoinHostPort(endpointURL.Hostname(), defaultOTLPGRPCPort)
+	}
+
+	if endpointURL.Scheme == "https" {
+		return address, &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return address, nil
+}
@@ -0,0 +1,110 @@
+package goteletracer
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNewConfigFromEnv tests mapping the standard OpenTelemetry environment variables onto Config
+func TestNewConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name            string
+		env             map[string]string
+		expectedErr     error
+		expectedService string
+		expectedAddress string
+		expectTLS       bool
+	}{
+		{
+			name: "generic endpoint",
+			env: map[string]string{
+				EnvServiceName:      "orders",
+				EnvExporterEndpoint: "collector:4317",
+			},
+			expectedService: "orders",
+			expectedAddress: "collector:4317",
+		},
+		{
+			name: "traces endpoint takes precedence",
+			env: map[string]string{
+				EnvServiceName:            "orders",
+				EnvExporterEndpoint:       "collector:4317",
+				EnvTracesExporterEndpoint: "http://traces-collector:4317",
+			},
+			expectedService: "orders",
+			expectedAddress: "traces-collector:4317",
+		},
+		{
+			name: "https endpoint enables TLS",
+			env: map[string]string{
+				EnvServiceName:      "orders",
+				EnvExporterEndpoint: "https://collector.example.com:4317",
+			},
+			expectedService: "orders",
+			expectedAddress: "collector.example.com:4317",
+			expectTLS:       true,
+		},
+		{
+			name: "https endpoint without a port uses 4317",
+			env: map[string]string{
+				EnvServiceName:      "orders",
+				EnvExporterEndpoint: "https://collector.example.com",
+			},
+			expectedService: "orders",
+			expectedAddress: "collector.example.com:4317",
+			expectTLS:       true,
+		},
+		{
+			name: "http IPv6 endpoint without a port uses 4317",
+			env: map[string]string{
+				EnvServiceName:      "orders",
+				EnvExporterEndpoint: "http://[::1]/",
+			},
+			expectedService: "orders",
+			expectedAddress: "[::1]:4317",
+		},
+		{
+			name: "missing service name",
+			env: map[string]string{
+				EnvExporterEndpoint: "collector:4317",
+			},
+			expectedErr: ErrEmptyServiceName,
+		},
+		{
+			name: "missing endpoint",
+			env: map[string]string{
+				EnvServiceName: "orders",
+			},
+			expectedErr: ErrEmptyExporterAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{EnvServiceName, EnvExporterEndpoint, EnvTracesExporterEndpoint} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			cfg, err := NewConfigFromEnv()
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if cfg.ServiceName != tt.expectedService {
+				t.Errorf("expected service name %q, got %q", tt.expectedService, cfg.ServiceName)
+			}
+			if cfg.ExporterGRPCAddress != tt.expectedAddress {
+				t.Errorf("expected exporter address %q, got %q", tt.expectedAddress, cfg.ExporterGRPCAddress)
+			}
+			if (cfg.TLS != nil) != tt.expectTLS {
+				t.Errorf("expected TLS=%v, got %v", tt.expectTLS, cfg.TLS != nil)
+			}
+		})
+	}
+}
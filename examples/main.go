@@ -346,12 +346,13 @@ func updateInventory(ctx context.Context, tracer trace.Tracer, orderID string) e
 	return nil
 }
 
-// sendNotification demonstrates notification sending with spans
-func sendNotification(ctx context.Context, tracer trace.Tracer, userID, orderID string) error {
-	ctx, span := tracer.Start(ctx, "send_notification")
-	defer span.End()
+// sendNotification demonstrates StartSpan, which records the returned error
+// and sets the span status without the usual boilerplate
+func sendNotification(ctx context.Context, tracer trace.Tracer, userID, orderID string) (err error) {
+	ctx, end := goteletracer.StartSpan(ctx, tracer, "send_notification")
+	defer end(&err)
 
-	span.SetAttributes(
+	trace.SpanFromContext(ctx).SetAttributes(
 		attribute.String("user.id", userID),
 		attribute.String("order.id", orderID),
 		attribute.String("notification.type", "email"),
@@ -362,13 +363,9 @@ func sendNotification(ctx context.Context, tracer trace.Tracer, userID, orderID
 
 	// Simulate occasional notification failure
 	if rand.Float32() < 0.15 { // 15% chance of failure
-		err := fmt.Errorf("email service unavailable")
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Notification sending failed")
-		return err
+		return fmt.Errorf("email service unavailable")
 	}
 
-	span.SetStatus(codes.Ok, "Notification sent successfully")
 	return nil
 }
 
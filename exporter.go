@@ -0,0 +1,503 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExportMode describes how the provider is currently handling finished spans
+type ExportMode int
+
+const (
+	// ExportModeNormal means spans are exported to the collector as usual
+	ExportModeNormal ExportMode = iota
+	// ExportModeLogOnly means exports keep failing and spans are additionally
+	// written through the Logger so some signal is retained locally
+	ExportModeLogOnly
+)
+
+// String returns the human readable name of the export mode
+func (m ExportMode) String() string {
+	switch m {
+	case ExportModeNormal:
+		return "normal"
+	case ExportModeLogOnly:
+		return "log-only"
+	default:
+		return "unknown"
+	}
+}
+
+// degradingExporter wraps a SpanExporter and switches to log-only mode after
+// a number of consecutive export failures. Exports are still attempted while
+// degraded so the exporter can recover once the collector is reachable again.
+type degradingExporter struct {
+	sdk_trace.SpanExporter
+	logger           Logger
+	failureThreshold int
+	successThreshold int
+
+	mu        sync.Mutex
+	failures  int
+	successes int
+	mode      ExportMode
+}
+
+// newDegradingExporter creates a degradingExporter around the given exporter
+func newDegradingExporter(exporter sdk_trace.SpanExporter, logger Logger, failureThreshold, successThreshold int) *degradingExporter {
+	if successThreshold <= 0 {
+		successThreshold = 1
+	}
+
+	return &degradingExporter{
+		SpanExporter:     exporter,
+		logger:           logger,
+		failureThreshold: failureThreshold,
+		successThreshold: successThreshold,
+	}
+}
+
+// ExportSpans exports spans through the wrapped exporter and tracks failures
+// to decide whether spans should be logged instead
+func (e *degradingExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		e.failures = 0
+		if e.mode == ExportModeLogOnly {
+			e.successes++
+			if e.successes >= e.successThreshold {
+				e.mode = ExportModeNormal
+				e.successes = 0
+				e.logger.Printf("goteletracer: exports recovered, leaving %s mode", ExportModeLogOnly)
+			}
+		}
+		return nil
+	}
+
+	e.successes = 0
+	e.failures++
+	if e.mode == ExportModeNormal && e.failures >= e.failureThreshold {
+		e.mode = ExportModeLogOnly
+		e.logger.Printf("goteletracer: %d consecutive export failures, entering %s mode: %v", e.failures, ExportModeLogOnly, err)
+	}
+
+	if e.mode == ExportModeLogOnly {
+		for _, span := range spans {
+			e.logger.Printf(
+				"goteletracer: span name=%q trace_id=%s span_id=%s duration=%s status=%s",
+				span.Name(),
+				span.SpanContext().TraceID(),
+				span.SpanContext().SpanID(),
+				span.EndTime().Sub(span.StartTime()),
+				span.Status().Code,
+			)
+		}
+	}
+
+	return err
+}
+
+// Mode returns the current export mode
+func (e *degradingExporter) Mode() ExportMode {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.mode
+}
+
+// fallbackExporter wraps a primary SpanExporter and routes spans to a fallback
+// exporter whenever the primary export fails
+type fallbackExporter struct {
+	primary  sdk_trace.SpanExporter
+	fallback sdk_trace.SpanExporter
+	exported *atomic.Int64
+	// owners is the number of fallbackExporters sharing the fallback exporter
+	owners *atomic.Int32
+}
+
+// newFallbackExporter creates a fallbackExporter around the given exporters
+func newFallbackExporter(primary, fallback sdk_trace.SpanExporter) *fallbackExporter {
+	e := &fallbackExporter{
+		primary:  primary,
+		fallback: fallback,
+		exported: new(atomic.Int64),
+		owners:   new(atomic.Int32),
+	}
+	e.owners.Store(1)
+
+	return e
+}
+
+// wrap returns a fallbackExporter around another primary exporter sharing the fallback
+// exporter and the exported counter
+func (e *fallbackExporter) wrap(primary sdk_trace.SpanExporter) *fallbackExporter {
+	e.owners.Add(1)
+
+	return &fallbackExporter{
+		primary:  primary,
+		fallback: e.fallback,
+		exported: e.exported,
+		owners:   e.owners,
+	}
+}
+
+// ExportSpans exports spans through the primary exporter and retries them on
+// the fallback exporter if the primary fails
+func (e *fallbackExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	err := e.primary.ExportSpans(ctx, spans)
+	if err == nil {
+		return nil
+	}
+
+	if fallbackErr := e.fallback.ExportSpans(ctx, spans); fallbackErr != nil {
+		return errors.Join(err, fmt.Errorf("fallback export failed: %w", fallbackErr))
+	}
+
+	e.exported.Add(int64(len(spans)))
+	return nil
+}
+
+// Shutdown shuts down the primary exporter, and the fallback exporter once every
+// fallbackExporter sharing it is shut down
+func (e *fallbackExporter) Shutdown(ctx context.Context) error {
+	err := e.primary.Shutdown(ctx)
+	if e.owners.Add(-1) > 0 {
+		return err
+	}
+
+	return errors.Join(err, e.fallback.Shutdown(ctx))
+}
+
+// clockExporter rewrites span and event timestamps using a custom clock before
+// handing spans to the wrapped exporter, making exported payloads reproducible
+type clockExporter struct {
+	sdk_trace.SpanExporter
+	now func() time.Time
+}
+
+// newClockExporter creates a clockExporter around the given exporter
+func newClockExporter(exporter sdk_trace.SpanExporter, now func() time.Time) *clockExporter {
+	return &clockExporter{
+		SpanExporter: exporter,
+		now:          now,
+	}
+}
+
+// ExportSpans restamps every span with times from the clock and exports them
+func (e *clockExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	stamped := make([]sdk_trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		stamped[i] = newClockSpan(span, e.now)
+	}
+
+	return e.SpanExporter.ExportSpans(ctx, stamped)
+}
+
+// clockSpan is a ReadOnlySpan whose timestamps were taken from a custom clock
+type clockSpan struct {
+	sdk_trace.ReadOnlySpan
+	startTime time.Time
+	endTime   time.Time
+	events    []sdk_trace.Event
+}
+
+// newClockSpan stamps the start, events and end of a span in that order
+func newClockSpan(span sdk_trace.ReadOnlySpan, now func() time.Time) *clockSpan {
+	stamped := &clockSpan{
+		ReadOnlySpan: span,
+		startTime:    now(),
+	}
+
+	for _, event := range span.Events() {
+		event.Time = now()
+		stamped.events = append(stamped.events, event)
+	}
+	stamped.endTime = now()
+
+	return stamped
+}
+
+// StartTime returns the clock based start time
+func (s *clockSpan) StartTime() time.Time {
+	return s.startTime
+}
+
+// EndTime returns the clock based end time
+func (s *clockSpan) EndTime() time.Time {
+	return s.endTime
+}
+
+// Events returns the span events with clock based timestamps
+func (s *clockSpan) Events() []sdk_trace.Event {
+	return s.events
+}
+
+// concurrencyLimitExporter bounds the number of in-flight exports to the wrapped
+// exporter. When the limit is reached it either blocks until a slot is free or
+// drops the batch, counting dropped spans.
+type concurrencyLimitExporter struct {
+	sdk_trace.SpanExporter
+	slots   chan struct{}
+	drop    bool
+	dropped *atomic.Int64
+}
+
+// newConcurrencyLimitExporter creates a concurrencyLimitExporter with the given limit
+func newConcurrencyLimitExporter(exporter sdk_trace.SpanExporter, limit int, drop bool) *concurrencyLimitExporter {
+	return &concurrencyLimitExporter{
+		SpanExporter: exporter,
+		slots:        make(chan struct{}, limit),
+		drop:         drop,
+		dropped:      new(atomic.Int64),
+	}
+}
+
+// wrap returns a concurrencyLimitExporter around another exporter taking its slots from
+// the same semaphore, so the limit bounds the exports of both together
+func (e *concurrencyLimitExporter) wrap(exporter sdk_trace.SpanExporter) *concurrencyLimitExporter {
+	return &concurrencyLimitExporter{
+		SpanExporter: exporter,
+		slots:        e.slots,
+		drop:         e.drop,
+		dropped:      e.dropped,
+	}
+}
+
+// ExportSpans exports spans once an in-flight slot is available
+func (e *concurrencyLimitExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	if e.drop {
+		select {
+		case e.slots <- struct{}{}:
+		default:
+			e.dropped.Add(int64(len(spans)))
+			return nil
+		}
+	} else {
+		select {
+		case e.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer func() { <-e.slots }()
+
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
+
+// countingExporter counts the spans the wrapped exporter exported and failed to export
+type countingExporter struct {
+	sdk_trace.SpanExporter
+	exported *atomic.Int64
+	dropped  *atomic.Int64
+}
+
+// newCountingExporter creates a countingExporter around the given exporter
+func newCountingExporter(exporter sdk_trace.SpanExporter) *countingExporter {
+	return &countingExporter{
+		SpanExporter: exporter,
+		exported:     new(atomic.Int64),
+		dropped:      new(atomic.Int64),
+	}
+}
+
+// wrap returns a countingExporter around another exporter adding to the same counters
+func (e *countingExporter) wrap(exporter sdk_trace.SpanExporter) *countingExporter {
+	return &countingExporter{
+		SpanExporter: exporter,
+		exported:     e.exported,
+		dropped:      e.dropped,
+	}
+}
+
+// ExportSpans exports spans and counts them as exported or dropped
+func (e *countingExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err != nil {
+		e.dropped.Add(int64(len(spans)))
+	} else {
+		e.exported.Add(int64(len(spans)))
+	}
+
+	return err
+}
+
+// swappableExporter delegates to an exporter that can be replaced while spans are
+// being exported, so the collector connection can be rebuilt without a new provider
+type swappableExporter struct {
+	mu       sync.RWMutex
+	exporter sdk_trace.SpanExporter
+}
+
+// newSwappableExporter creates a swappableExporter around the given exporter
+func newSwappableExporter(exporter sdk_trace.SpanExporter) *swappableExporter {
+	return &swappableExporter{exporter: exporter}
+}
+
+// ExportSpans exports spans through the current exporter
+func (e *swappableExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.exporter.ExportSpans(ctx, spans)
+}
+
+// Shutdown shuts down the current exporter
+func (e *swappableExporter) Shutdown(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.exporter.Shutdown(ctx)
+}
+
+// swap replaces the current exporter once in-flight exports finish and returns the previous one
+func (e *swappableExporter) swap(exporter sdk_trace.SpanExporter) sdk_trace.SpanExporter {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	previous := e.exporter
+	e.exporter = exporter
+	return previous
+}
+
+// shutdownRetryBackoff is the wait before the first retry of a failed export during
+// shutdown, doubled for each further retry
+const shutdownRetryBackoff = 100 * time.Millisecond
+
+// shutdownRetryExporter retries failed exports of the wrapped exporter while the
+// provider is shutting down, so the final flush survives a short collector blip
+type shutdownRetryExporter struct {
+	sdk_trace.SpanExporter
+	retries      int
+	shuttingDown *atomic.Bool
+}
+
+// newShutdownRetryExporter creates a shutdownRetryExporter retrying up to retries times
+func newShutdownRetryExporter(exporter sdk_trace.SpanExporter, retries int) *shutdownRetryExporter {
+	return &shutdownRetryExporter{
+		SpanExporter: exporter,
+		retries:      retries,
+		shuttingDown: new(atomic.Bool),
+	}
+}
+
+// wrap returns a shutdownRetryExporter around another exporter whose retries are
+// enabled by the same startShutdown call
+func (e *shutdownRetryExporter) wrap(exporter sdk_trace.SpanExporter) *shutdownRetryExporter {
+	return &shutdownRetryExporter{
+		SpanExporter: exporter,
+		retries:      e.retries,
+		shuttingDown: e.shuttingDown,
+	}
+}
+
+// ExportSpans exports spans, retrying failures with a backoff once shutdown started
+func (e *shutdownRetryExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+
+	backoff := shutdownRetryBackoff
+	for attempt := 0; err != nil && attempt < e.retries && e.shuttingDown.Load(); attempt++ {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		}
+		backoff *= 2
+
+		err = e.SpanExporter.ExportSpans(ctx, spans)
+	}
+
+	return err
+}
+
+// startShutdown enables retries for the exports of the final flush
+func (e *shutdownRetryExporter) startShutdown() {
+	e.shuttingDown.Store(true)
+}
+
+// exportChain builds the wrappers the configuration puts around the exporters of a
+// provider. The first exporter creates them and later ones, such as those of
+// ExporterAddresses, share their counters and state, so Stats, ExportMode, the
+// fallback, MaxInFlightExports and shutdown retries cover every exporter.
+type exportChain struct {
+	cfg           *Config
+	counter       *countingExporter
+	shutdownRetry *shutdownRetryExporter
+	degraders     []*degradingExporter
+	fallback      *fallbackExporter
+	limiter       *concurrencyLimitExporter
+}
+
+// newExportChain creates an exportChain for a resolved configuration
+func newExportChain(cfg *Config) *exportChain {
+	return &exportChain{cfg: cfg}
+}
+
+// wrap returns the exporter wrapped as configured
+func (c *exportChain) wrap(exporter sdk_trace.SpanExporter) sdk_trace.SpanExporter {
+	cfg := c.cfg
+
+	// Retry the exports of the final flush on Shutdown
+	if cfg.ShutdownRetries > 0 {
+		if c.shutdownRetry == nil {
+			c.shutdownRetry = newShutdownRetryExporter(exporter, cfg.ShutdownRetries)
+			exporter = c.shutdownRetry
+		} else {
+			exporter = c.shutdownRetry.wrap(exporter)
+		}
+	}
+
+	// Count the spans reaching the exporter
+	if c.counter == nil {
+		c.counter = newCountingExporter(exporter)
+		exporter = c.counter
+	} else {
+		exporter = c.counter.wrap(exporter)
+	}
+
+	// Restamp spans with the custom clock for reproducible exports
+	if cfg.Clock != nil {
+		exporter = newClockExporter(exporter, cfg.Clock)
+	}
+
+	// Fall back to logging spans on repeated export failures, tracked per exporter
+	if cfg.DegradeAfterFailures > 0 {
+		degrader := newDegradingExporter(exporter, cfg.Logger, cfg.DegradeAfterFailures, cfg.RecoverAfterSuccesses)
+		c.degraders = append(c.degraders, degrader)
+		exporter = degrader
+	}
+
+	// Route spans to the fallback exporter when the export fails
+	if cfg.FallbackExporter != nil {
+		if c.fallback == nil {
+			c.fallback = newFallbackExporter(exporter, cfg.FallbackExporter)
+			exporter = c.fallback
+		} else {
+			exporter = c.fallback.wrap(exporter)
+		}
+	}
+
+	// Protect the collectors from too many concurrent exports; each exporter has its own
+	// batch processor, so the limit is shared for it to bound exports across all of them
+	if cfg.MaxInFlightExports > 0 {
+		if c.limiter == nil {
+			c.limiter = newConcurrencyLimitExporter(exporter, cfg.MaxInFlightExports, cfg.DropOnExportLimit)
+			exporter = c.limiter
+		} else {
+			exporter = c.limiter.wrap(exporter)
+		}
+	}
+
+	return exporter
+}
@@ -0,0 +1,536 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// stubExporter is a SpanExporter that returns queued errors on export
+type stubExporter struct {
+	mu       sync.Mutex
+	errs     []error
+	exported int
+	calls    int
+	names    []string
+}
+
+// ExportSpans pops the next queued error, counting spans on success
+func (e *stubExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls++
+	if len(e.errs) > 0 {
+		err := e.errs[0]
+		e.errs = e.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+
+	e.exported += len(spans)
+	for _, span := range spans {
+		e.names = append(e.names, span.Name())
+	}
+	return nil
+}
+
+// Shutdown does nothing
+func (e *stubExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// captureLogger is a Logger that stores formatted messages
+type captureLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// Printf records the formatted message
+func (l *captureLogger) Printf(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+// count returns the number of recorded messages
+func (l *captureLogger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.messages)
+}
+
+// testSpans returns a single ended span snapshot for exporter tests
+func testSpans() []sdk_trace.ReadOnlySpan {
+	return tracetest.SpanStubs{{Name: "test-span"}}.Snapshots()
+}
+
+// TestExportModeString tests the string representation of export modes
+func TestExportModeString(t *testing.T) {
+	tests := []struct {
+		mode     ExportMode
+		expected string
+	}{
+		{mode: ExportModeNormal, expected: "normal"},
+		{mode: ExportModeLogOnly, expected: "log-only"},
+		{mode: ExportMode(42), expected: "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.String(); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+// TestDegradingExporter tests switching to log-only mode and recovering
+func TestDegradingExporter(t *testing.T) {
+	errExport := errors.New("collector unavailable")
+
+	tests := []struct {
+		name             string
+		errs             []error
+		failureThreshold int
+		successThreshold int
+		expectedModes    []ExportMode
+		expectedLogs     int
+	}{
+		{
+			name:             "stays normal below threshold",
+			errs:             []error{errExport, errExport, nil},
+			failureThreshold: 3,
+			expectedModes:    []ExportMode{ExportModeNormal, ExportModeNormal, ExportModeNormal},
+			expectedLogs:     0,
+		},
+		{
+			name:             "degrades after threshold and logs spans",
+			errs:             []error{errExport, errExport, errExport},
+			failureThreshold: 2,
+			expectedModes:    []ExportMode{ExportModeNormal, ExportModeLogOnly, ExportModeLogOnly},
+			expectedLogs:     1 + 1 + 1, // mode change, then one span per degraded export
+		},
+		{
+			name:             "recovers after a successful export",
+			errs:             []error{errExport, nil},
+			failureThreshold: 1,
+			expectedModes:    []ExportMode{ExportModeLogOnly, ExportModeNormal},
+			expectedLogs:     1 + 1 + 1, // mode change, span, recovery
+		},
+		{
+			name:             "recovery requires configured successes",
+			errs:             []error{errExport, nil, nil},
+			failureThreshold: 1,
+			successThreshold: 2,
+			expectedModes:    []ExportMode{ExportModeLogOnly, ExportModeLogOnly, ExportModeNormal},
+			expectedLogs:     1 + 1 + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &captureLogger{}
+			exporter := newDegradingExporter(&stubExporter{errs: tt.errs}, logger, tt.failureThreshold, tt.successThreshold)
+
+			for i, expected := range tt.expectedModes {
+				_ = exporter.ExportSpans(context.Background(), testSpans())
+				if mode := exporter.Mode(); mode != expected {
+					t.Errorf("export %d: expected mode %v, got %v", i+1, expected, mode)
+				}
+			}
+
+			if logger.count() != tt.expectedLogs {
+				t.Errorf("expected %d log messages, got %d: %v", tt.expectedLogs, logger.count(), logger.messages)
+			}
+		})
+	}
+}
+
+// TestTracerProviderExportMode tests the export mode reported by the provider
+func TestTracerProviderExportMode(t *testing.T) {
+	provider := &TracerProvider{}
+	if mode := provider.ExportMode(); mode != ExportModeNormal {
+		t.Errorf("expected %v without degradation configured, got %v", ExportModeNormal, mode)
+	}
+
+	healthy := newDegradingExporter(&stubExporter{}, &captureLogger{}, 1, 1)
+	degraded := newDegradingExporter(&stubExporter{errs: []error{errors.New("down")}}, &captureLogger{}, 1, 1)
+	provider.degraders = []*degradingExporter{healthy, degraded}
+	if mode := provider.ExportMode(); mode != ExportModeNormal {
+		t.Errorf("expected %v before failures, got %v", ExportModeNormal, mode)
+	}
+
+	_ = degraded.ExportSpans(context.Background(), testSpans())
+	if mode := provider.ExportMode(); mode != ExportModeLogOnly {
+		t.Errorf("expected %v after failures, got %v", ExportModeLogOnly, mode)
+	}
+}
+
+// TestFallbackExporter tests routing spans to the fallback exporter on primary failure
+func TestFallbackExporter(t *testing.T) {
+	errPrimary := errors.New("primary down")
+	errFallback := errors.New("fallback down")
+
+	tests := []struct {
+		name             string
+		primaryErrs      []error
+		fallbackErrs     []error
+		expectErr        bool
+		expectedPrimary  int
+		expectedFallback int
+	}{
+		{
+			name:            "primary succeeds",
+			expectedPrimary: 1,
+		},
+		{
+			name:             "primary fails and fallback succeeds",
+			primaryErrs:      []error{errPrimary},
+			expectedFallback: 1,
+		},
+		{
+			name:         "both exporters fail",
+			primaryErrs:  []error{errPrimary},
+			fallbackErrs: []error{errFallback},
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubExporter{errs: tt.primaryErrs}
+			fallback := &stubExporter{errs: tt.fallbackErrs}
+			exporter := newFallbackExporter(primary, fallback)
+
+			err := exporter.ExportSpans(context.Background(), testSpans())
+			if tt.expectErr {
+				if !errors.Is(err, errPrimary) || !errors.Is(err, errFallback) {
+					t.Errorf("expected both errors to be reported, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if primary.exported != tt.expectedPrimary {
+				t.Errorf("expected %d spans on primary, got %d", tt.expectedPrimary, primary.exported)
+			}
+			if fallback.exported != tt.expectedFallback {
+				t.Errorf("expected %d spans on fallback, got %d", tt.expectedFallback, fallback.exported)
+			}
+			if got := exporter.exported.Load(); got != int64(tt.expectedFallback) {
+				t.Errorf("expected fallback counter %d, got %d", tt.expectedFallback, got)
+			}
+		})
+	}
+}
+
+// TestClockExporter tests that exported spans carry timestamps from the custom clock
+func TestClockExporter(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tick := 0
+	clock := func() time.Time {
+		tick++
+		return base.Add(time.Duration(tick) * time.Second)
+	}
+
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		Clock:               clock,
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "golden")
+	span.AddEvent("checkpoint")
+	span.End()
+
+	if err := provider.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	if !spans[0].StartTime.Equal(base.Add(1 * time.Second)) {
+		t.Errorf("unexpected start time %v", spans[0].StartTime)
+	}
+	if len(spans[0].Events) != 1 || !spans[0].Events[0].Time.Equal(base.Add(2*time.Second)) {
+		t.Errorf("unexpected events %v", spans[0].Events)
+	}
+	if !spans[0].EndTime.Equal(base.Add(3 * time.Second)) {
+		t.Errorf("unexpected end time %v", spans[0].EndTime)
+	}
+}
+
+// blockingExporter is a SpanExporter that blocks exports until released
+type blockingExporter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+// ExportSpans signals the start of the export and waits for release
+func (e *blockingExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	e.started <- struct{}{}
+	<-e.release
+	return nil
+}
+
+// Shutdown does nothing
+func (e *blockingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// TestConcurrencyLimitExporterDrop tests that exports beyond the limit are dropped and counted
+func TestConcurrencyLimitExporterDrop(t *testing.T) {
+	inner := &blockingExporter{started: make(chan struct{}, 1), release: make(chan struct{})}
+	exporter := newConcurrencyLimitExporter(inner, 1, true)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- exporter.ExportSpans(context.Background(), testSpans())
+	}()
+	<-inner.started
+
+	if err := exporter.ExportSpans(context.Background(), testSpans()); err != nil {
+		t.Errorf("expected dropped export to return nil, got %v", err)
+	}
+	if got := exporter.dropped.Load(); got != 1 {
+		t.Errorf("expected 1 dropped span, got %d", got)
+	}
+
+	close(inner.release)
+	if err := <-done; err != nil {
+		t.Errorf("expected in-flight export to succeed, got %v", err)
+	}
+}
+
+// TestConcurrencyLimitExporterBlock tests that exports beyond the limit wait for a slot
+func TestConcurrencyLimitExporterBlock(t *testing.T) {
+	inner := &blockingExporter{started: make(chan struct{}, 2), release: make(chan struct{})}
+	exporter := newConcurrencyLimitExporter(inner, 1, false)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- exporter.ExportSpans(context.Background(), testSpans())
+	}()
+	<-inner.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := exporter.ExportSpans(ctx, testSpans()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected blocked export to time out, got %v", err)
+	}
+	if got := exporter.dropped.Load(); got != 0 {
+		t.Errorf("expected no dropped spans in blocking mode, got %d", got)
+	}
+
+	close(inner.release)
+	if err := <-done; err != nil {
+		t.Errorf("expected in-flight export to succeed, got %v", err)
+	}
+
+	if err := exporter.ExportSpans(context.Background(), testSpans()); err != nil {
+		t.Errorf("expected export to succeed once a slot is free, got %v", err)
+	}
+}
+
+// peakExporter is a SpanExporter tracking the highest number of concurrent exports
+type peakExporter struct {
+	inFlight atomic.Int64
+	peak     atomic.Int64
+	// release, when set, holds every export until it is closed instead of sleeping
+	release chan struct{}
+}
+
+// ExportSpans records the number of exports in flight while simulating a slow export
+func (e *peakExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	current := e.inFlight.Add(1)
+	defer e.inFlight.Add(-1)
+
+	for {
+		peak := e.peak.Load()
+		if current <= peak || e.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	if e.release != nil {
+		<-e.release
+		return nil
+	}
+
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+// Shutdown does nothing
+func (e *peakExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// TestMaxInFlightExportsBound tests that the batch processors of more exporters than the limit
+// allows export exactly up to the limit at once
+func TestMaxInFlightExportsBound(t *testing.T) {
+	const (
+		limit     = 3
+		exporters = 6
+	)
+
+	// Every batch processor exports to the same exporter, which holds the exports so
+	// that all of them would be in flight at once without the limit
+	inner := &peakExporter{release: make(chan struct{})}
+	spanExporters := make([]sdk_trace.SpanExporter, exporters)
+	for i := range spanExporters {
+		spanExporters[i] = inner
+	}
+
+	provider, err := buildTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		MaxInFlightExports:  limit,
+		MaxExportBatchSize:  1,
+		BatchTimeout:        time.Millisecond,
+	}, spanExporters)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	for range 10 {
+		_, span := provider.Tracer().Start(context.Background(), "span")
+		span.End()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for inner.inFlight.Load() < limit && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// Give the remaining processors the chance to exceed the limit
+	time.Sleep(50 * time.Millisecond)
+
+	if peak := inner.peak.Load(); peak != limit {
+		t.Errorf("expected a peak of %d concurrent exports while held, got %d", limit, peak)
+	}
+
+	close(inner.release)
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if peak := inner.peak.Load(); peak != limit {
+		t.Errorf("expected a peak of %d concurrent exports, got %d", limit, peak)
+	}
+	if exported := provider.Stats().Exported; exported != 10*exporters {
+		t.Errorf("expected every span exported to every exporter, got %d", exported)
+	}
+}
+
+// TestMaxInFlightExportsAcrossExporters tests that the limit bounds the exports of the
+// batch processors of every exporter together
+func TestMaxInFlightExportsAcrossExporters(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        int
+		expectedPeak int64
+	}{
+		{name: "unlimited exporters export concurrently", limit: 0, expectedPeak: 2},
+		{name: "limit shared by every exporter", limit: 1, expectedPeak: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Both processors export to the same exporter, which tracks their overlap
+			inner := &peakExporter{}
+
+			provider, err := buildTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				MaxInFlightExports:  tt.limit,
+				MaxExportBatchSize:  1,
+				BatchTimeout:        time.Millisecond,
+			}, []sdk_trace.SpanExporter{inner, inner})
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+			defer provider.Shutdown(context.Background())
+
+			for range 200 {
+				_, span := provider.Tracer().Start(context.Background(), "span")
+				span.End()
+			}
+			if err := provider.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("expected no flush error, got %v", err)
+			}
+
+			if peak := inner.peak.Load(); peak != tt.expectedPeak {
+				t.Errorf("expected a peak of %d concurrent exports, got %d", tt.expectedPeak, peak)
+			}
+			if exported := provider.Stats().Exported; exported != 400 {
+				t.Errorf("expected every span exported to both exporters, got %d", exported)
+			}
+		})
+	}
+}
+
+// shutdownCountingExporter is a SpanExporter counting Shutdown calls
+type shutdownCountingExporter struct {
+	stubExporter
+	shutdowns atomic.Int32
+}
+
+// Shutdown counts the call
+func (e *shutdownCountingExporter) Shutdown(ctx context.Context) error {
+	e.shutdowns.Add(1)
+	return nil
+}
+
+// TestExportChainSharedAcrossExporters tests that every exporter gets the configured wrappers
+// sharing the counters and the fallback exporter
+func TestExportChainSharedAcrossExporters(t *testing.T) {
+	errDown := errors.New("collector unavailable")
+	fallback := &shutdownCountingExporter{}
+	primary := &stubExporter{}
+	secondary := &stubExporter{errs: []error{errDown}}
+
+	provider, err := buildTracerProvider(context.Background(), &Config{
+		ServiceName:          "test-service",
+		ExporterGRPCAddress:  "localhost:4317",
+		FallbackExporter:     fallback,
+		DegradeAfterFailures: 1,
+		Logger:               &captureLogger{},
+	}, []sdk_trace.SpanExporter{primary, secondary})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "span")
+	span.End()
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	stats := provider.Stats()
+	if stats.Exported != 1 || stats.Dropped != 1 || stats.FallbackExported != 1 {
+		t.Errorf("expected 1 exported, 1 dropped and 1 fallback span, got %+v", stats)
+	}
+	if mode := provider.ExportMode(); mode != ExportModeLogOnly {
+		t.Errorf("expected the failing exporter to degrade the provider, got %v", mode)
+	}
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+	if shutdowns := fallback.shutdowns.Load(); shutdowns != 1 {
+		t.Errorf("expected the shared fallback exporter to be shut down once, got %d", shutdowns)
+	}
+}
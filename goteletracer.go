@@ -3,57 +3,478 @@ package goteletracer
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
 )
 
+// ShutdownSpanName is the name of the span emitted on Shutdown when EmitShutdownSpan is set
+const ShutdownSpanName = "goteletracer.shutdown"
+
+// DeploymentTimestampKey is the resource attribute key holding the process start time
+const DeploymentTimestampKey = attribute.Key("deployment.timestamp")
+
+// processStartTime approximates the process start time for deployment.timestamp
+var processStartTime = time.Now()
+
+// newResource creates the tracer resource; tests replace it to simulate detector failures
+var newResource = resource.New
+
+// newOTLPExporter creates the OTLP GRPC exporter; tests replace it to simulate exporter failures
+var newOTLPExporter = otlptracegrpc.New
+
 // Common errors returned by the tracer package
 var (
-	ErrNilConfig              = errors.New("config cannot be nil")
-	ErrEmptyServiceName       = errors.New("service name cannot be empty")
-	ErrEmptyExporterAddress   = errors.New("exporter GRPC address cannot be empty")
-	ErrInvalidExporterAddress = errors.New("exporter GRPC address is invalid")
+	ErrNilConfig               = errors.New("config cannot be nil")
+	ErrEmptyServiceName        = errors.New("service name cannot be empty")
+	ErrEmptyExporterAddress    = errors.New("exporter GRPC address cannot be empty")
+	ErrInvalidExporterAddress  = errors.New("exporter GRPC address is invalid")
+	ErrInvalidSamplingRatio    = errors.New("sampling ratio must be between 0 and 1")
+	ErrSpanRetentionDisabled   = errors.New("span retention is disabled")
+	ErrReservedResourceKey     = errors.New("resource attribute key is reserved")
+	ErrInvalidBatchSize        = errors.New("max export batch size cannot exceed max queue size")
+	ErrInvalidCompression      = errors.New("compression must be \"gzip\" or \"none\"")
+	ErrExporterTimeout         = errors.New("timed out creating the tracer exporter")
+	ErrWriterExporterAddresses = errors.New("exporter addresses cannot be used with a writer exporter")
+	ErrInvalidShutdownTimeout  = errors.New("shutdown timeout must be positive")
+)
+
+// ProviderStage identifies the step of provider creation that failed
+type ProviderStage string
+
+// Stages of provider creation reported by ProviderError
+const (
+	// StageResource is the creation of the resource describing the service
+	StageResource ProviderStage = "resource"
+	// StageGRPC is the set up of the GRPC connection to the collector, including its credentials
+	StageGRPC ProviderStage = "grpc"
+	// StageExporter is the creation of the span exporter
+	StageExporter ProviderStage = "exporter"
+)
+
+// ProviderError is returned when creating a provider fails after the config was validated,
+// telling with errors.As at which stage it failed
+type ProviderError struct {
+	Stage ProviderStage
+	Err   error
+}
+
+// Error returns the failing stage and the underlying error
+func (e *ProviderError) Error() string {
+	switch e.Stage {
+	case StageResource:
+		return fmt.Sprintf("failed to create tracer resource: %v", e.Err)
+	case StageGRPC:
+		return fmt.Sprintf("failed to create GRPC connection: %v", e.Err)
+	case StageExporter:
+		return fmt.Sprintf("failed to create tracer exporter: %v", e.Err)
+	default:
+		return fmt.Sprintf("failed to create tracer provider at %s stage: %v", e.Stage, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Compression values accepted by Config.Compression
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
 )
 
+// reservedResourceKeys are resource attribute keys set by the provider itself, which
+// ResourceAttributes cannot override
+var reservedResourceKeys = []attribute.Key{
+	semconv.ServiceNameKey,
+	DeploymentTimestampKey,
+}
+
 // Config holds the configuration for the OpenTelemetry tracer
 type Config struct {
 	// ServiceName is the name of the service that will be used in telemetry data
-	ServiceName string
-	// ExporterGRPCAddress is the address of the OTLP GRPC exporter endpoint
-	ExporterGRPCAddress string
+	ServiceName string `json:"service_name"`
+	// ExporterGRPCAddress is the address of the OTLP GRPC exporter endpoint, e.g. "collector:4317",
+	// or a unix domain socket such as "unix:///var/run/otel.sock"
+	ExporterGRPCAddress string `json:"exporter_grpc_address"`
+	// ExporterAddresses are additional OTLP GRPC endpoints every span is also exported to,
+	// e.g. to dual-write during a collector migration. Each gets its own connection and
+	// batch processor using the same transport, batching and export settings as
+	// ExporterGRPCAddress, and is covered by Stats, HealthCheck and Reconnect.
+	// They cannot be combined with WriterExporter.
+	ExporterAddresses []string `json:"exporter_addresses"`
+	// TLS secures the connection to the exporter endpoint
+	// The connection is insecure if not specified, unless the WithCACertFile option is used
+	TLS *tls.Config `json:"-"`
+	// InsecureSkipVerify secures the connection with TLS without verifying the collector's
+	// certificate chain and host name, e.g. for staging collectors with self-signed certificates.
+	// The traffic is encrypted but anyone able to intercept it can impersonate the collector,
+	// so never enable it in production. It is applied on top of TLS and WithCACertFile if set.
+	InsecureSkipVerify bool `json:"insecure_skip_verify"`
+	// ServiceVersion is the optional service.version resource attribute, e.g. "1.4.2"
+	ServiceVersion string `json:"service_version"`
+	// InstrumentationVersion is the instrumentation scope version of the provider's tracer,
+	// e.g. the version of the library or module producing spans, identifying it in the backend
+	InstrumentationVersion string `json:"instrumentation_version"`
+	// Environment is the optional deployment.environment.name resource attribute, e.g. "production"
+	// It is the semconv v1.27 name of deployment.environment, which can still be set with
+	// ResourceAttributes for backends expecting the old key
+	Environment string `json:"environment"`
+	// ServiceInstanceID is the optional service.instance.id resource attribute telling apart
+	// the instances of a service, e.g. the pod name in Kubernetes
+	ServiceInstanceID string `json:"service_instance_id"`
+	// AutoInstanceID sets service.instance.id to a random UUID generated when the provider
+	// is created if ServiceInstanceID is empty
+	AutoInstanceID bool `json:"auto_instance_id"`
+	// ResourceAttributes are added to the resource describing the service, e.g.
+	// service.namespace or cloud.region
+	// ServiceVersion, Environment and ServiceInstanceID take precedence over the same keys set here
+	// Reserved keys such as service.name are rejected; use the WithResourceAttributes option to override them
+	ResourceAttributes map[string]string `json:"resource_attributes"`
+	// DetectHost, DetectProcess and DetectContainer add host, process and container
+	// metadata detected at startup to the resource
+	// Disabled by default to keep the resource minimal
+	DetectHost      bool `json:"detect_host"`
+	DetectProcess   bool `json:"detect_process"`
+	DetectContainer bool `json:"detect_container"`
+	// Detectors are custom resource detectors run at startup, e.g. for a cloud provider
+	// Configured resource attributes take precedence over detected ones
+	Detectors []resource.Detector `json:"-"`
+	// Headers are sent as GRPC metadata with every export request, e.g. an API key
+	// required by a managed collector
+	Headers map[string]string `json:"headers"`
+	// Compression compresses export requests to the GRPC exporter endpoint, either "gzip" or "none"
+	// Default is "none" if not specified
+	Compression string `json:"compression"`
+	// DisableRetry turns off retrying failed exports to the GRPC exporter endpoint
+	// Retries are enabled by default, like in the OTLP exporter
+	DisableRetry bool `json:"disable_retry"`
+	// RetryInitialInterval is the wait before the first retry of a failed export
+	// Default is 5 seconds if not specified
+	RetryInitialInterval time.Duration `json:"retry_initial_interval"`
+	// RetryMaxInterval caps the exponentially growing wait between retries
+	// Default is 30 seconds if not specified
+	RetryMaxInterval time.Duration `json:"retry_max_interval"`
+	// RetryMaxElapsedTime is the maximum time spent retrying an export before its spans are dropped
+	// Default is 1 minute if not specified
+	RetryMaxElapsedTime time.Duration `json:"retry_max_elapsed_time"`
+	// KeepAliveTime is the interval of keepalive pings on the collector connection while
+	// exports are in flight, detecting connections silently dropped by load balancers
+	// Default is 5 minutes if not specified, the shortest interval GRPC servers accept by default
+	KeepAliveTime time.Duration `json:"keep_alive_time"`
+	// KeepAliveTimeout is the wait for a keepalive ping ack before the connection is closed
+	// Default is 20 seconds if not specified
+	KeepAliveTimeout time.Duration `json:"keep_alive_timeout"`
+	// PermitWithoutStream also sends keepalive pings on idle connections
+	// The collector must allow it, otherwise it closes the connection
+	PermitWithoutStream bool `json:"permit_without_stream"`
+	// BlockOnConnect makes NewTracerProvider wait until the collector connection is ready,
+	// failing fast on startup instead of connecting lazily on the first export.
+	// The globals are only replaced once the connection is ready.
+	BlockOnConnect bool `json:"block_on_connect"`
+	// ConnectTimeout bounds the wait of BlockOnConnect and the dials of Reconnect
+	// Default is 10 seconds if not specified
+	ConnectTimeout time.Duration `json:"connect_timeout"`
 	// ShutdownTimeout defines the maximum time to wait for graceful shutdown
 	// Default is 30 seconds if not specified
-	ShutdownTimeout time.Duration
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	// MaxShutdownTimeout caps ShutdownTimeout and the deadline of any context passed to
+	// Shutdown, so a hung collector cannot block process exit indefinitely
+	// Default is 2 minutes if not specified
+	MaxShutdownTimeout time.Duration `json:"max_shutdown_timeout"`
+	// FlushTimeout bounds flushing buffered spans on Shutdown independently of the whole
+	// shutdown, leaving the rest of the shutdown budget to closing the connection
+	// Zero lets the flush use the whole shutdown budget
+	FlushTimeout time.Duration `json:"flush_timeout"`
+	// ShutdownRetries retries failed exports of the final flush on Shutdown up to this many
+	// times, waiting 100ms before the first retry and doubling the wait for each further one,
+	// so buffered spans survive a short collector outage. Retries stop at the shutdown deadline
+	// Zero disables retries
+	ShutdownRetries int `json:"shutdown_retries"`
+	// Logger receives internal diagnostics such as spans logged while exports are degraded,
+	// and errors reported by OpenTelemetry, such as failed exports, unless DisableGlobal is set
+	// Defaults to the standard library logger if not specified
+	Logger Logger `json:"-"`
+	// DegradeAfterFailures is the number of consecutive export failures after which
+	// the provider switches to log-only mode and writes spans through Logger
+	// Zero disables degradation
+	DegradeAfterFailures int `json:"degrade_after_failures"`
+	// RecoverAfterSuccesses is the number of consecutive successful exports required
+	// to leave log-only mode
+	// Default is 1 if not specified
+	RecoverAfterSuccesses int `json:"recover_after_successes"`
+	// FallbackExporter receives spans whenever the primary OTLP export fails,
+	// e.g. a stdout or file exporter used as a safety net during collector outages.
+	// With ExporterAddresses, each failing collector routes its spans to it.
+	FallbackExporter sdk_trace.SpanExporter `json:"-"`
+	// BatchTimeout is the maximum delay before buffered spans are exported
+	// Default is the SDK default (5 seconds) if not specified
+	BatchTimeout time.Duration `json:"batch_timeout"`
+	// ExportTimeout is the maximum duration of a single export
+	// Default is the SDK default (30 seconds) if not specified
+	ExportTimeout time.Duration `json:"export_timeout"`
+	// MaxQueueSize is the maximum number of spans buffered for export; spans beyond it are dropped
+	// Default is the SDK default (2048) if not specified
+	MaxQueueSize int `json:"max_queue_size"`
+	// MaxExportBatchSize is the maximum number of spans per export and cannot exceed MaxQueueSize
+	// Default is the SDK default (512) if not specified
+	MaxExportBatchSize int `json:"max_export_batch_size"`
+	// MaxAttributesPerSpan, MaxEventsPerSpan and MaxLinksPerSpan cap the attributes, events
+	// and links recorded per span; extra ones are dropped and counted on the span
+	// Default is the SDK default (128 each) if not specified
+	MaxAttributesPerSpan int `json:"max_attributes_per_span"`
+	MaxEventsPerSpan     int `json:"max_events_per_span"`
+	MaxLinksPerSpan      int `json:"max_links_per_span"`
+	// UseSimpleProcessor exports every span synchronously when it ends instead of batching,
+	// so spans show up immediately while debugging locally
+	// Each End blocks on a full export round trip, which is far too slow for production
+	// The batch settings above are ignored in this mode
+	UseSimpleProcessor bool `json:"use_simple_processor"`
+	// MaxInFlightExports caps the number of concurrent export calls, shared by the collector
+	// and every ExporterAddresses collector. Each exporter's processor, batch or simple,
+	// already exports one call at a time, so the limit only matters when it is below the
+	// number of exporters sharing it, i.e. with ExporterAddresses.
+	// Zero means unlimited
+	MaxInFlightExports int `json:"max_in_flight_exports"`
+	// DropOnExportLimit drops batches instead of waiting when MaxInFlightExports is reached
+	// Dropped spans are counted in Stats
+	DropOnExportLimit bool `json:"drop_on_export_limit"`
+	// SamplingRatio samples root spans at this ratio (0.0-1.0) with ParentBased(TraceIDRatioBased),
+	// so child spans follow the decision of their parent and traces stay complete
+	// Zero keeps the default of sampling every span
+	// It is also the ratio of span kinds missing from SpanKindSamplingRatios
+	SamplingRatio float64 `json:"sampling_ratio"`
+	// RecordAllSampleRatio records every span locally, e.g. for RetainSpans, while only
+	// exporting root spans at this ratio (0.0-1.0); child spans follow their parent
+	// Zero disables it. It takes precedence over SamplingRatio.
+	RecordAllSampleRatio float64 `json:"record_all_sample_ratio"`
+	// SpanKindSamplingRatios sets a sampling ratio (0.0-1.0) per span kind, e.g. keep
+	// every server span while sampling internal spans at 1%
+	// Kinds without a ratio use SamplingRatio or are always sampled. When SamplingRatio,
+	// RecordAllSampleRatio or InheritParentSampling is set, the ratios only decide root spans
+	// and child spans follow their parent. Otherwise each span is sampled independently of
+	// its parent, so traces may be partially recorded when ratios differ between kinds
+	SpanKindSamplingRatios map[trace.SpanKind]float64 `json:"span_kind_sampling_ratios"`
+	// ScopeSamplingRatios sets a sampling ratio (0.0-1.0) per instrumentation scope name, e.g.
+	// to let a shared library sample its own spans differently than the host application
+	// It applies to tracers obtained with NamedTracer for that name and takes precedence over
+	// SpanKindSamplingRatios. With InheritParentSampling it only decides root spans
+	ScopeSamplingRatios map[string]float64 `json:"scope_sampling_ratios"`
+	// RecordGoroutineID sets a best-effort goroutine identifier attribute on every span
+	// Go hides goroutine IDs, so the value is parsed from the runtime stack on each Start,
+	// which adds noticeable overhead and may break with future Go versions
+	// Intended for debugging concurrency issues only
+	RecordGoroutineID bool `json:"record_goroutine_id"`
+	// Clock overrides the timestamps of exported spans and their events
+	// Each exported span is restamped at export time by calling Clock for its start,
+	// every event and its end, so a deterministic clock yields byte-stable payloads
+	// This is intended for golden-file tests only, never for production use
+	Clock func() time.Time `json:"-"`
+	// RecordDeploymentTimestamp adds a deployment.timestamp resource attribute holding
+	// the process start time as an RFC3339 string, to correlate traces with deploys
+	RecordDeploymentTimestamp bool `json:"record_deployment_timestamp"`
+	// StrictResource fails provider creation when the tracer resource cannot be created
+	// By default the failure is logged and a partial resource, or one holding only the
+	// service name, is used so tracing keeps working
+	StrictResource bool `json:"strict_resource"`
+	// WriterExporter, when set, replaces the GRPC exporter and writes spans as length-delimited
+	// OTLP protobuf ExportTraceServiceRequest messages, e.g. to a pipe read by a sidecar
+	// ExporterGRPCAddress is not required in this mode. On Shutdown the writer is flushed
+	// if it has a Flush() error method and closed if it implements io.Closer
+	WriterExporter io.Writer `json:"-"`
+	// MaxDistinctSpanNames caps the number of distinct span names produced by the provider
+	// Once reached, spans with new names are renamed to "other" to protect backend cardinality
+	// Zero disables the limit
+	MaxDistinctSpanNames int `json:"max_distinct_span_names"`
+	// SpanNameValidator reports whether a span name follows the naming convention, e.g. "verb.noun"
+	// Non-conforming names are counted in Stats as SpanNameViolations
+	SpanNameValidator func(name string) bool `json:"-"`
+	// SpanNameNormalizer rewrites span names at Start, e.g. to enforce a naming convention
+	// When SpanNameValidator is set only non-conforming names are rewritten, otherwise every name is
+	// Leave nil to only count violations. Renaming happens before MaxDistinctSpanNames is applied
+	SpanNameNormalizer func(name string) string `json:"-"`
+	// InheritParentSampling reuses the sampling decision carried by the parent span in the
+	// context instead of consulting the sampler again for every child span
+	// Root spans are still decided by the configured sampler (ParentBased semantics), which
+	// makes child span creation cheaper and keeps traces complete
+	InheritParentSampling bool `json:"inherit_parent_sampling"`
+	// RecordSamplingProbability sets a sampling.probability attribute on sampled root spans
+	// holding the ratio of the sampler that kept them, e.g. for span-to-metrics extrapolation
+	// The value is the ScopeSamplingRatios entry of the tracer's scope, else the
+	// SpanKindSamplingRatios entry for the span kind, else RecordAllSampleRatio or
+	// SamplingRatio when set, in that order, else 1. Child spans are not annotated as they
+	// follow the trace of their root
+	RecordSamplingProbability bool `json:"record_sampling_probability"`
+	// Sampler replaces the sampler derived from the config when set, e.g. for bespoke logic
+	// keeping every error while sampling other spans at 1%. It overrides SamplingRatio,
+	// RecordAllSampleRatio, SpanKindSamplingRatios, ScopeSamplingRatios,
+	// RecordSamplingProbability and InheritParentSampling. The WithSampler option takes
+	// precedence over it.
+	Sampler sdk_trace.Sampler `json:"-"`
+	// RequestIDAttributeKey is the span attribute key used for request IDs stored with WithRequestID
+	// Default is "request.id" if not specified
+	RequestIDAttributeKey string `json:"request_id_attribute_key"`
+	// DropAttributeKeys lists span attribute keys removed before export, e.g. internal IPs
+	// Keys ending with "*" are prefix matches, e.g. "net.host.*"
+	DropAttributeKeys []string `json:"drop_attribute_keys"`
+	// ExtraSpanProcessors are registered after the exporting span processor, e.g. for custom
+	// enrichment in OnStart or inspection in OnEnd. They are shut down and flushed with the provider
+	ExtraSpanProcessors []sdk_trace.SpanProcessor `json:"-"`
+	// PreExportSpanProcessors are called before the exporting span processor: their OnStart
+	// and OnEnd complete before the span is handed to export, e.g. for enrichment that must
+	// be visible to the exporter or bookkeeping that must happen before a synchronous export.
+	// ExtraSpanProcessors are called after it instead. They are shut down and flushed with
+	// the provider, before the exporting span processor
+	PreExportSpanProcessors []sdk_trace.SpanProcessor `json:"-"`
+	// SpanNameSanitizer rewrites span names before export, e.g. replacing IDs with a
+	// placeholder to keep their cardinality low. Unlike SpanNameNormalizer, samplers and
+	// RetainSpans still see the original name
+	SpanNameSanitizer func(name string) string `json:"-"`
+	// AttributeSanitizer rewrites span attributes before export, e.g. masking emails
+	// It is called for every attribute left after DropAttributeKeys
+	AttributeSanitizer func(attr attribute.KeyValue) attribute.KeyValue `json:"-"`
+	// MaxSpanAttributeBytes is the estimated total size budget for the attributes of a span
+	// When exceeded, the largest attributes are dropped at export and the number dropped is
+	// recorded in the goteletracer.budget_dropped_attributes attribute
+	// Zero disables the budget
+	MaxSpanAttributeBytes int `json:"max_span_attribute_bytes"`
+	// EmitShutdownSpan emits a final goteletracer.shutdown span at the start of Shutdown,
+	// recording the provider uptime and export counters, before the remaining spans are flushed
+	// The span is subject to the configured sampler like any other root span
+	EmitShutdownSpan bool `json:"emit_shutdown_span"`
+	// LinkAttributeKeys maps attribute keys holding hex upstream trace IDs to the attribute
+	// keys holding the matching span IDs. Spans started with such attributes get a link to
+	// the upstream span, and the two attributes are removed before export. Invalid IDs are
+	// skipped, logged and counted in Stats, and their attributes are kept
+	LinkAttributeKeys map[string]string `json:"link_attribute_keys"`
+	// LogMalformedContext logs incoming trace headers that cannot be extracted
+	// Such extractions always start a new trace and are counted in Stats
+	LogMalformedContext bool `json:"log_malformed_context"`
+	// MarkMalformedContext sets the goteletracer.malformed_parent_context attribute on spans
+	// started from a context whose incoming trace headers could not be extracted
+	MarkMalformedContext bool `json:"mark_malformed_context"`
+	// HeartbeatInterval emits a tiny goteletracer.heartbeat span at this interval, giving a
+	// continuous liveness signal for the pipeline even when the service is idle
+	// Zero disables the heartbeat
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+	// Propagators selects the context propagation formats by name: "tracecontext", "baggage",
+	// "b3" and "jaeger". Incoming contexts are extracted with each in order
+	// Default is "tracecontext" and "baggage" if not specified. Baggage is only propagated
+	// when "baggage" is listed, e.g. "tracecontext" alone writes no baggage header
+	Propagators []string `json:"propagators"`
+	// DisableGlobal keeps the provider from replacing the global otel tracer provider,
+	// text map propagator and error handler, e.g. when a process creates providers for several services
+	// Tracer and NamedTracer keep working; InjectMetadata and ExtractMetadata use the
+	// global propagator and are not affected by this provider
+	DisableGlobal bool `json:"disable_global"`
+	// Disabled turns tracing off, e.g. behind a feature flag: NewTracerProvider returns a
+	// provider whose tracers are noop, without validating the rest of the config or
+	// connecting to the collector, and whose Shutdown returns nil
+	Disabled bool `json:"disabled"`
+	// RetainSpans keeps the last N ended spans in memory so they can be inspected with SpansJSON
+	// Zero disables retention
+	RetainSpans int `json:"retain_spans"`
+	// CollectLatencyStats records the duration of every ended span in an in-process
+	// histogram per span name, read with LatencyStats
+	CollectLatencyStats bool `json:"collect_latency_stats"`
+	// TrackQueueDepth counts the spans entering and leaving the export queue so Stats and
+	// MetricsHandler report an estimate of its depth; it has no effect with UseSimpleProcessor
+	TrackQueueDepth bool `json:"track_queue_depth"`
+}
+
+// Logger is the minimal logging interface used for internal diagnostics.
+// It is satisfied by *log.Logger from the standard library.
+type Logger interface {
+	Printf(format string, args ...any)
 }
 
 // TracerProvider wraps the OpenTelemetry tracer provider with additional functionality
 type TracerProvider struct {
 	tracer          trace.Tracer
 	provider        *sdk_trace.TracerProvider
-	exporter        *otlptrace.Exporter
+	exporter        sdk_trace.SpanExporter
+	counter         *countingExporter
+	shutdownRetry   *shutdownRetryExporter
+	degraders       []*degradingExporter
+	fallback        *fallbackExporter
+	limiter         *concurrencyLimitExporter
+	linker          *linkAttributesProcessor
+	nameConvention  *spanNameConventionProcessor
+	propagator      *checkedPropagator
+	recorder        *spanRecorder
+	latency         *latencyStatsProcessor
+	queues          []*queueTracker
+	memory          *tracetest.InMemoryExporter
 	grpcConn        *grpc.ClientConn
+	extraConns      []*grpc.ClientConn
+	extraSwappables []*swappableExporter
+	connMu          sync.Mutex
+	closed          bool
+	swappable       *swappableExporter
+	config          *Config
+	options         providerOptions
 	shutdownOnce    sync.Once
 	shutdownErr     error
 	shutdownTimeout time.Duration
+	flushTimeout    time.Duration
+	maxShutdown     time.Duration
+	namedTracers    sync.Map
+	scopeSampling   map[string]*scopeSampling
+	spanAttributes  []attribute.KeyValue
+	startTime       time.Time
+	shutdownSpan    bool
+	heartbeatStop   chan struct{}
+	heartbeatDone   chan struct{}
 }
 
-// validateConfig validates the provided configuration
-func validateConfig(cfg *Config) error {
+// tracerKey identifies a cached named tracer by its whole instrumentation scope
+type tracerKey struct {
+	name       string
+	version    string
+	schemaURL  string
+	attributes attribute.Distinct
+}
+
+// ValidateConfig performs all static validation of the config that NewTracerProvider does,
+// including the exporter address checks, without dialing a collector or creating
+// exporters, e.g. to check a config in CI or early at startup. It returns the same
+// errors, such as ErrEmptyServiceName or ErrInvalidExporterAddress.
+func ValidateConfig(cfg *Config) error {
+	// Disabled tracing uses none of the other settings
+	if cfg != nil && cfg.Disabled {
+		return nil
+	}
+
+	// The GRPC address is not used when spans are written to WriterExporter
+	return validateConfigFields(cfg, cfg == nil || cfg.WriterExporter == nil)
+}
+
+// validateConfigFields validates the configuration, checking the exporter address only when requested
+func validateConfigFields(cfg *Config, checkAddress bool) error {
 	if cfg == nil {
 		return ErrNilConfig
 	}
@@ -62,112 +483,822 @@ func validateConfig(cfg *Config) error {
 		return ErrEmptyServiceName
 	}
 
-	if strings.TrimSpace(cfg.ExporterGRPCAddress) == "" {
+	if cfg.WriterExporter != nil && len(cfg.ExporterAddresses) > 0 {
+		return ErrWriterExporterAddresses
+	}
+
+	if checkAddress {
+		if err := validateExporterAddress(cfg.ExporterGRPCAddress); err != nil {
+			return err
+		}
+		for _, address := range cfg.ExporterAddresses {
+			if err := validateExporterAddress(address); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch cfg.Compression {
+	case "", CompressionNone, CompressionGzip:
+	default:
+		return fmt.Errorf("%w, got %q", ErrInvalidCompression, cfg.Compression)
+	}
+
+	if !validSamplingRatio(cfg.SamplingRatio) || !validSamplingRatio(cfg.RecordAllSampleRatio) {
+		return ErrInvalidSamplingRatio
+	}
+
+	for _, ratio := range cfg.SpanKindSamplingRatios {
+		if !validSamplingRatio(ratio) {
+			return ErrInvalidSamplingRatio
+		}
+	}
+
+	for _, ratio := range cfg.ScopeSamplingRatios {
+		if !validSamplingRatio(ratio) {
+			return ErrInvalidSamplingRatio
+		}
+	}
+
+	if _, err := newPropagator(cfg.Propagators); err != nil {
+		return err
+	}
+
+	maxQueueSize := cfg.MaxQueueSize
+	if maxQueueSize <= 0 {
+		maxQueueSize = sdk_trace.DefaultMaxQueueSize
+	}
+	if cfg.MaxExportBatchSize > maxQueueSize {
+		return fmt.Errorf("%w: %d > %d", ErrInvalidBatchSize, cfg.MaxExportBatchSize, maxQueueSize)
+	}
+
+	for key := range cfg.ResourceAttributes {
+		if slices.Contains(reservedResourceKeys, attribute.Key(key)) {
+			return fmt.Errorf("%w: %q, use the WithResourceAttributes option to override it", ErrReservedResourceKey, key)
+		}
+	}
+
+	return nil
+}
+
+// validateExporterAddress validates the OTLP GRPC exporter address
+func validateExporterAddress(address string) error {
+	if strings.TrimSpace(address) == "" {
 		return ErrEmptyExporterAddress
 	}
 
+	// Unix domain socket targets carry a path instead of a host and port
+	if path, ok := unixSocketPath(address); ok {
+		if path == "" {
+			return fmt.Errorf("%w: unix socket targets must be \"unix:path\" or \"unix:///absolute/path\"", ErrInvalidExporterAddress)
+		}
+		return nil
+	}
+
 	// Basic address validation - check if it contains host:port format
-	if !strings.Contains(cfg.ExporterGRPCAddress, ":") {
+	if !strings.Contains(address, ":") {
 		return ErrInvalidExporterAddress
 	}
 
+	// Targets with a URI scheme such as "dns:///host:port" follow their resolver's syntax
+	if strings.Contains(address, "://") {
+		return nil
+	}
+
+	// IPv6 hosts must be bracketed, otherwise the port cannot be told apart
+	if isUnbracketedIPv6(address) {
+		return fmt.Errorf("%w: IPv6 hosts must be bracketed, e.g. \"[::1]:4317\"", ErrInvalidExporterAddress)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidExporterAddress, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("%w: missing host in %q", ErrInvalidExporterAddress, address)
+	}
+
+	if portNumber, err := strconv.Atoi(port); err != nil || portNumber < 1 || portNumber > 65535 {
+		return fmt.Errorf("%w: port must be a number between 1 and 65535, got %q", ErrInvalidExporterAddress, port)
+	}
+
+	return nil
+}
+
+// unixSocketPath returns the socket path of a "unix:path" or "unix:///absolute/path" target,
+// or an empty path for malformed unix targets. ok is false for targets of other schemes.
+func unixSocketPath(address string) (path string, ok bool) {
+	rest, ok := strings.CutPrefix(address, "unix:")
+	if !ok {
+		return "", false
+	}
+
+	// "unix://" must be followed by an absolute path, as unix targets have no authority
+	if authorityPath, hasAuthority := strings.CutPrefix(rest, "//"); hasAuthority {
+		if !strings.HasPrefix(authorityPath, "/") {
+			return "", true
+		}
+		rest = authorityPath
+	}
+
+	return rest, true
+}
+
+// isUnbracketedIPv6 reports whether the address looks like an IPv6 host with a port
+// but without the surrounding brackets, e.g. "::1:4317"
+func isUnbracketedIPv6(address string) bool {
+	return strings.Count(address, ":") > 1 && !strings.HasPrefix(address, "[")
+}
+
+// resolveConfig returns a copy of the config with defaults applied to unset fields
+func resolveConfig(cfg *Config) Config {
+	resolved := *cfg
+
+	// Set default shutdown timeout if not provided
+	if resolved.ShutdownTimeout <= 0 {
+		resolved.ShutdownTimeout = defaultShutdownTimeout()
+	}
+
+	if resolved.MaxShutdownTimeout <= 0 {
+		resolved.MaxShutdownTimeout = defaultMaxShutdownTimeout()
+	}
+
+	if resolved.ShutdownTimeout > resolved.MaxShutdownTimeout {
+		resolved.ShutdownTimeout = resolved.MaxShutdownTimeout
+	}
+
+	if resolved.Logger == nil {
+		resolved.Logger = log.Default()
+	}
+
+	if resolved.RequestIDAttributeKey == "" {
+		resolved.RequestIDAttributeKey = string(DefaultRequestIDKey)
+	}
+
+	if resolved.DegradeAfterFailures > 0 && resolved.RecoverAfterSuccesses <= 0 {
+		resolved.RecoverAfterSuccesses = 1
+	}
+
+	// Retry defaults match the OTLP exporter defaults
+	if resolved.RetryInitialInterval <= 0 {
+		resolved.RetryInitialInterval = 5 * time.Second
+	}
+
+	if resolved.RetryMaxInterval <= 0 {
+		resolved.RetryMaxInterval = 30 * time.Second
+	}
+
+	if resolved.RetryMaxElapsedTime <= 0 {
+		resolved.RetryMaxElapsedTime = time.Minute
+	}
+
+	if resolved.ConnectTimeout <= 0 {
+		resolved.ConnectTimeout = 10 * time.Second
+	}
+
+	if resolved.KeepAliveTime <= 0 {
+		resolved.KeepAliveTime = 5 * time.Minute
+	}
+
+	if resolved.KeepAliveTimeout <= 0 {
+		resolved.KeepAliveTimeout = 20 * time.Second
+	}
+
+	return resolved
+}
+
+// shutdownTimeoutMu guards shutdownTimeoutDefault
+var shutdownTimeoutMu sync.Mutex
+
+// shutdownTimeoutDefault is the shutdown timeout of configs without ShutdownTimeout
+var shutdownTimeoutDefault = 30 * time.Second
+
+// SetDefaultShutdownTimeout changes the shutdown timeout used by providers created
+// afterward whose Config.ShutdownTimeout is zero, 30 seconds unless changed. It is
+// still capped by MaxShutdownTimeout. Non-positive durations return
+// ErrInvalidShutdownTimeout and leave the default unchanged.
+func SetDefaultShutdownTimeout(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%w, got %s", ErrInvalidShutdownTimeout, d)
+	}
+
+	shutdownTimeoutMu.Lock()
+	defer shutdownTimeoutMu.Unlock()
+
+	shutdownTimeoutDefault = d
 	return nil
 }
 
 // defaultShutdownTimeout returns the default shutdown timeout
 func defaultShutdownTimeout() time.Duration {
-	return 30 * time.Second
+	shutdownTimeoutMu.Lock()
+	defer shutdownTimeoutMu.Unlock()
+
+	return shutdownTimeoutDefault
+}
+
+// defaultMaxShutdownTimeout returns the default upper bound of the shutdown timeout
+func defaultMaxShutdownTimeout() time.Duration {
+	return 2 * time.Minute
 }
 
 // NewTracer creates a new OpenTelemetry tracer with the provided configuration.
 // Returns a noop tracer if config is nil.
 // For production use, use NewTracerProvider for better resource management.
 func NewTracer(cfg *Config) trace.Tracer {
-	if cfg == nil {
-		return noop.NewTracerProvider().Tracer("")
-	}
+	// Fallback to noop tracer to maintain backward compatibility
+	tracer, _ := NewTracerOrError(cfg)
+	return tracer
+}
 
+// NewTracerOrError creates a new OpenTelemetry tracer like NewTracer, but also returns
+// the error that made it fall back to a noop tracer, so callers can log it or fail startup.
+// The returned tracer is always usable.
+func NewTracerOrError(cfg *Config) (trace.Tracer, error) {
 	tracerProvider, err := NewTracerProvider(cfg)
 	if err != nil {
-		// Fallback to noop tracer to maintain backward compatibility
-		return noop.NewTracerProvider().Tracer("")
+		return noop.NewTracerProvider().Tracer(""), err
 	}
 
-	return tracerProvider.Tracer()
+	return tracerProvider.Tracer(), nil
 }
 
 // NewTracerProvider creates a new TracerProvider with the provided configuration.
 // This is the recommended way to create tracers as it provides better resource management.
 func NewTracerProvider(cfg *Config) (*TracerProvider, error) {
-	if err := validateConfig(cfg); err != nil {
+	return NewTracerProviderWithOptions(cfg)
+}
+
+// NewTracerProviderWithOptions creates a new TracerProvider like NewTracerProvider,
+// with options overriding the defaults derived from the configuration.
+func NewTracerProviderWithOptions(cfg *Config, opts ...Option) (*TracerProvider, error) {
+	if err := ValidateConfig(cfg); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	// Set default shutdown timeout if not provided
-	shutdownTimeout := cfg.ShutdownTimeout
-	if shutdownTimeout <= 0 {
-		shutdownTimeout = defaultShutdownTimeout()
+	if cfg.Disabled {
+		return NewNoopTracerProvider(), nil
+	}
+
+	var options providerOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Create resource with service information
-	tracerResource, err := resource.New(
-		ctx,
-		resource.WithAttributes(semconv.ServiceNameKey.String(cfg.ServiceName)),
-	)
+	// Write spans to the configured writer instead of dialing a collector
+	if cfg.WriterExporter != nil {
+		writerExporter, err := newWriterExporter(ctx, cfg.WriterExporter)
+		if err != nil {
+			return nil, &ProviderError{Stage: StageExporter, Err: err}
+		}
+
+		return newTracerProvider(ctx, cfg, writerExporter, opts...)
+	}
+
+	// Dial every collector before building the provider, so a failure leaves nothing behind
+	addresses := append([]string{cfg.ExporterGRPCAddress}, cfg.ExporterAddresses...)
+	conns := make([]*grpc.ClientConn, 0, len(addresses))
+	swappables := make([]*swappableExporter, 0, len(addresses))
+	tracerExporters := make([]sdk_trace.SpanExporter, 0, len(addresses))
+	for _, address := range addresses {
+		grpcConn, tracerExporter, err := dialExporter(ctx, cfg, address, options)
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return nil, err
+		}
+
+		// Let Reconnect replace the exporter without rebuilding the provider
+		swappable := newSwappableExporter(tracerExporter)
+		conns = append(conns, grpcConn)
+		swappables = append(swappables, swappable)
+		tracerExporters = append(tracerExporters, swappable)
+	}
+
+	tracerProvider, err := buildTracerProvider(ctx, cfg, tracerExporters, opts...)
+	if err != nil {
+		// Clean up connections on error
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return nil, err
+	}
+
+	tracerProvider.grpcConn = conns[0]
+	tracerProvider.swappable = swappables[0]
+	tracerProvider.extraConns = conns[1:]
+	tracerProvider.extraSwappables = swappables[1:]
+	tracerProvider.config = dialConfig(cfg)
+	tracerProvider.options = options
+
+	// Fail fast when the collector cannot be reached on startup
+	if cfg.BlockOnConnect {
+		resolved := resolveConfig(cfg)
+		connectCtx, connectCancel := context.WithTimeout(context.Background(), resolved.ConnectTimeout)
+		defer connectCancel()
+
+		if err := tracerProvider.HealthCheck(connectCtx); err != nil {
+			tracerProvider.Shutdown(context.Background())
+			return nil, fmt.Errorf("failed to connect to collector: %w", err)
+		}
+	}
+
+	tracerProvider.setGlobals(cfg)
+
+	return tracerProvider, nil
+}
+
+// dialConfig returns a copy of the config Reconnect dials the collectors with, so later
+// changes the caller makes to the config, including its addresses, headers and TLS
+// settings, do not affect the provider
+func dialConfig(cfg *Config) *Config {
+	copied := *cfg
+	copied.ExporterAddresses = slices.Clone(cfg.ExporterAddresses)
+	copied.Headers = maps.Clone(cfg.Headers)
+	if cfg.TLS != nil {
+		copied.TLS = cfg.TLS.Clone()
+	}
+
+	return &copied
+}
+
+// dialExporter creates the GRPC connection to the collector at the address and the OTLP exporter using it
+func dialExporter(ctx context.Context, cfg *Config, address string, options providerOptions) (*grpc.ClientConn, sdk_trace.SpanExporter, error) {
+	transportCredentials, err := newTransportCredentials(cfg, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create tracer resource: %w", err)
+		return nil, nil, &ProviderError{Stage: StageGRPC, Err: err}
 	}
 
 	// Create GRPC connection with timeout
+	resolved := resolveConfig(cfg)
 	grpcConn, err := grpc.NewClient(
-		cfg.ExporterGRPCAddress,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		address,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithKeepaliveParams(keepaliveParams(&resolved)),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GRPC connection: %w", err)
+		return nil, nil, &ProviderError{Stage: StageGRPC, Err: err}
 	}
 
 	// Create OTLP exporter
-	tracerExporter, err := otlptracegrpc.New(
-		ctx,
-		otlptracegrpc.WithGRPCConn(grpcConn),
-	)
+	tracerExporter, err := newOTLPExporter(ctx, grpcExporterOptions(cfg, grpcConn)...)
 	if err != nil {
 		// Clean up connection on error
 		grpcConn.Close()
-		return nil, fmt.Errorf("failed to create tracer exporter: %w", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: %w", ErrExporterTimeout, err)
+		}
+		return nil, nil, &ProviderError{Stage: StageExporter, Err: err}
+	}
+
+	return grpcConn, tracerExporter, nil
+}
+
+// NewNoopTracerProvider creates a TracerProvider whose tracers are noop, with no exporter
+// or collector connection, e.g. for tests and environments without tracing, so code can
+// depend on *TracerProvider uniformly. All its methods are safe to call: ForceFlush and
+// Shutdown return nil. It is what NewTracerProvider returns for Disabled configs.
+func NewNoopTracerProvider() *TracerProvider {
+	return &TracerProvider{
+		tracer:          noop.NewTracerProvider().Tracer(""),
+		shutdownTimeout: defaultShutdownTimeout(),
+		maxShutdown:     defaultMaxShutdownTimeout(),
+		startTime:       time.Now(),
+	}
+}
+
+// newExportProcessor creates the processor handing finished spans to the exporter,
+// filtering them on their way according to the configuration
+func newExportProcessor(cfg *Config, spanExporter sdk_trace.SpanExporter) sdk_trace.SpanProcessor {
+	var batchOptions []sdk_trace.BatchSpanProcessorOption
+	if cfg.BatchTimeout > 0 {
+		batchOptions = append(batchOptions, sdk_trace.WithBatchTimeout(cfg.BatchTimeout))
+	}
+	if cfg.ExportTimeout > 0 {
+		batchOptions = append(batchOptions, sdk_trace.WithExportTimeout(cfg.ExportTimeout))
+	}
+	if cfg.MaxQueueSize > 0 {
+		batchOptions = append(batchOptions, sdk_trace.WithMaxQueueSize(cfg.MaxQueueSize))
+	}
+	if cfg.MaxExportBatchSize > 0 {
+		batchOptions = append(batchOptions, sdk_trace.WithMaxExportBatchSize(cfg.MaxExportBatchSize))
+	}
+
+	var exportProcessor sdk_trace.SpanProcessor
+	if cfg.UseSimpleProcessor {
+		exportProcessor = sdk_trace.NewSimpleSpanProcessor(spanExporter)
+	} else {
+		exportProcessor = sdk_trace.NewBatchSpanProcessor(spanExporter, batchOptions...)
+	}
+	if cfg.MaxSpanAttributeBytes > 0 {
+		exportProcessor = newAttributeBudgetProcessor(exportProcessor, cfg.MaxSpanAttributeBytes)
+	}
+	if cfg.SpanNameSanitizer != nil || cfg.AttributeSanitizer != nil {
+		exportProcessor = newSanitizeProcessor(exportProcessor, cfg.SpanNameSanitizer, cfg.AttributeSanitizer)
+	}
+	if len(cfg.DropAttributeKeys) > 0 {
+		exportProcessor = newDropAttributesProcessor(exportProcessor, cfg.DropAttributeKeys)
+	}
+	if len(cfg.LinkAttributeKeys) > 0 {
+		exportProcessor = newLinkedAttributesProcessor(exportProcessor, cfg.LinkAttributeKeys)
+	}
+
+	return exportProcessor
+}
+
+// grpcExporterOptions returns the OTLP GRPC exporter options derived from the configuration
+func grpcExporterOptions(cfg *Config, grpcConn *grpc.ClientConn) []otlptracegrpc.Option {
+	resolved := resolveConfig(cfg)
+
+	options := []otlptracegrpc.Option{
+		otlptracegrpc.WithGRPCConn(grpcConn),
+		otlptracegrpc.WithRetry(retryConfig(&resolved)),
+	}
+
+	if len(resolved.Headers) > 0 {
+		options = append(options, otlptracegrpc.WithHeaders(resolved.Headers))
+	}
+
+	if resolved.Compression == CompressionGzip {
+		options = append(options, otlptracegrpc.WithCompressor(CompressionGzip))
+	}
+
+	return options
+}
+
+// retryConfig returns the export retry policy of a resolved configuration
+func retryConfig(cfg *Config) otlptracegrpc.RetryConfig {
+	return otlptracegrpc.RetryConfig{
+		Enabled:         !cfg.DisableRetry,
+		InitialInterval: cfg.RetryInitialInterval,
+		MaxInterval:     cfg.RetryMaxInterval,
+		MaxElapsedTime:  cfg.RetryMaxElapsedTime,
+	}
+}
+
+// keepaliveParams returns the keepalive parameters of the collector connection of a resolved configuration
+func keepaliveParams(cfg *Config) keepalive.ClientParameters {
+	return keepalive.ClientParameters{
+		Time:                cfg.KeepAliveTime,
+		Timeout:             cfg.KeepAliveTimeout,
+		PermitWithoutStream: cfg.PermitWithoutStream,
+	}
+}
+
+// NewTracerProviderWithRetry creates a new TracerProvider, retrying up to attempts times
+// with the given backoff between attempts, for services that start before the collector.
+// GRPC connections are established lazily, so each attempt waits up to ConnectTimeout for
+// the collectors to be reachable, as with BlockOnConnect. Failed attempts leave the global
+// provider untouched. Invalid configurations are returned immediately without retrying.
+// The context is only used to cancel the wait between attempts.
+func NewTracerProviderWithRetry(ctx context.Context, cfg *Config, attempts int, backoff time.Duration) (*TracerProvider, error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	// Probe the collectors on every attempt
+	probeCfg := *cfg
+	probeCfg.BlockOnConnect = true
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		tracerProvider, err := NewTracerProvider(&probeCfg)
+		if err == nil {
+			return tracerProvider, nil
+		}
+		lastErr = err
+
+		if attempt == attempts {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+	}
+
+	return nil, fmt.Errorf("failed to create tracer provider after %d attempts: %w", attempts, lastErr)
+}
+
+// newTracerProvider builds a TracerProvider around an already created span exporter and
+// installs it as the global provider. The config is expected to be validated by the caller.
+func newTracerProvider(ctx context.Context, cfg *Config, tracerExporter sdk_trace.SpanExporter, opts ...Option) (*TracerProvider, error) {
+	tp, err := buildTracerProvider(ctx, cfg, []sdk_trace.SpanExporter{tracerExporter}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	tp.setGlobals(cfg)
+
+	return tp, nil
+}
+
+// setGlobals installs the provider, its propagator and an error handler logging through
+// the Logger as the OpenTelemetry globals, unless the caller keeps several providers side
+// by side. It is called once the provider is ready, so a provider that fails to start
+// never replaces the globals.
+func (tp *TracerProvider) setGlobals(cfg *Config) {
+	if cfg.DisableGlobal {
+		return
+	}
+
+	logger := resolveConfig(cfg).Logger
+	otel.SetTracerProvider(tp.provider)
+	otel.SetTextMapPropagator(tp.propagator)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Printf("goteletracer: %v", err)
+	}))
+}
+
+// buildTracerProvider builds a TracerProvider around already created span exporters without
+// touching the OpenTelemetry globals. Every span is exported to each of them; the first one
+// is the primary exporter, the others those of ExporterAddresses.
+func buildTracerProvider(ctx context.Context, cfg *Config, tracerExporters []sdk_trace.SpanExporter, opts ...Option) (*TracerProvider, error) {
+	// Fill in defaults for unset fields
+	resolved := resolveConfig(cfg)
+	cfg = &resolved
+
+	var options providerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Create resource with service information
+	resourceAttributes := []attribute.KeyValue{semconv.ServiceNameKey.String(cfg.ServiceName)}
+	if cfg.RecordDeploymentTimestamp {
+		resourceAttributes = append(resourceAttributes, DeploymentTimestampKey.String(processStartTime.UTC().Format(time.RFC3339)))
+	}
+	for key, value := range cfg.ResourceAttributes {
+		resourceAttributes = append(resourceAttributes, attribute.String(key, value))
+	}
+	if cfg.ServiceVersion != "" {
+		resourceAttributes = append(resourceAttributes, semconv.ServiceVersionKey.String(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		resourceAttributes = append(resourceAttributes, semconv.DeploymentEnvironmentNameKey.String(cfg.Environment))
+	}
+	if cfg.ServiceInstanceID != "" {
+		resourceAttributes = append(resourceAttributes, semconv.ServiceInstanceIDKey.String(cfg.ServiceInstanceID))
+	} else if cfg.AutoInstanceID {
+		resourceAttributes = append(resourceAttributes, semconv.ServiceInstanceIDKey.String(uuid.NewString()))
+	}
+	resourceAttributes = append(resourceAttributes, options.resourceAttributes...)
+
+	logger := cfg.Logger
+
+	// Run detectors first so configured attributes override detected ones
+	var resourceOptions []resource.Option
+	if cfg.DetectHost {
+		resourceOptions = append(resourceOptions, resource.WithHost())
+	}
+	if cfg.DetectProcess {
+		resourceOptions = append(resourceOptions, resource.WithProcess())
+	}
+	if cfg.DetectContainer {
+		resourceOptions = append(resourceOptions, resource.WithContainer())
+	}
+	if len(cfg.Detectors) > 0 {
+		resourceOptions = append(resourceOptions, resource.WithDetectors(cfg.Detectors...))
+	}
+	resourceOptions = append(resourceOptions, resource.WithAttributes(resourceAttributes...))
+
+	tracerResource, err := newResource(ctx, resourceOptions...)
+	if err != nil {
+		if cfg.StrictResource {
+			return nil, &ProviderError{Stage: StageResource, Err: err}
+		}
+
+		// Keep tracing with whatever resource could be built
+		if tracerResource == nil {
+			tracerResource = resource.NewSchemaless(semconv.ServiceNameKey.String(cfg.ServiceName))
+		}
+		logger.Printf("goteletracer: failed to create tracer resource, using a partial resource: %v", err)
+	}
+
+	// Wrap every exporter as configured, sharing counters between them
+	chain := newExportChain(cfg)
+	spanExporters := make([]sdk_trace.SpanExporter, len(tracerExporters))
+	for i, tracerExporter := range tracerExporters {
+		spanExporters[i] = chain.wrap(tracerExporter)
+	}
+
+	// Sample root spans by ratio when configured, otherwise always sample
+	sampler := sdk_trace.AlwaysSample()
+	defaultRatio := 1.0
+	if cfg.SamplingRatio > 0 {
+		sampler = sdk_trace.TraceIDRatioBased(cfg.SamplingRatio)
+		defaultRatio = cfg.SamplingRatio
+	}
+
+	// Record the spans left out of the ratio instead of dropping them
+	if cfg.RecordAllSampleRatio > 0 {
+		sampler = newRecordingSampler(cfg.RecordAllSampleRatio)
+		defaultRatio = cfg.RecordAllSampleRatio
+	}
+	probability := func(trace.SpanKind) float64 { return defaultRatio }
+
+	// Sample per span kind when ratios are configured
+	if len(cfg.SpanKindSamplingRatios) > 0 {
+		sampler = newSpanKindSampler(cfg.SpanKindSamplingRatios, sampler)
+		probability = func(kind trace.SpanKind) float64 {
+			if ratio, ok := cfg.SpanKindSamplingRatios[kind]; ok {
+				return ratio
+			}
+			return defaultRatio
+		}
+	}
+
+	// Let tracers with a scope ratio decide their own spans
+	var scopeSamplings map[string]*scopeSampling
+	if len(cfg.ScopeSamplingRatios) > 0 {
+		scopeSamplings = make(map[string]*scopeSampling, len(cfg.ScopeSamplingRatios))
+		for scope, ratio := range cfg.ScopeSamplingRatios {
+			scopeSamplings[scope] = newScopeSampling(ratio)
+		}
+		sampler = scopeSampler{fallback: sampler}
+	}
+
+	// Record the probability root spans were sampled with
+	if cfg.RecordSamplingProbability {
+		sampler = newProbabilitySampler(sampler, probability)
+	}
+
+	// Reuse the root decision carried in the context for child spans, also
+	// recording the children of spans that were only recorded
+	if cfg.RecordAllSampleRatio > 0 {
+		recordOnly := newRecordingSampler(0)
+		sampler = sdk_trace.ParentBased(
+			sampler,
+			sdk_trace.WithLocalParentNotSampled(recordOnly),
+			sdk_trace.WithRemoteParentNotSampled(recordOnly),
+		)
+	} else if cfg.InheritParentSampling || cfg.SamplingRatio > 0 {
+		sampler = sdk_trace.ParentBased(sampler)
+	}
+
+	// An explicit sampler replaces the one derived from the config
+	if cfg.Sampler != nil {
+		sampler = cfg.Sampler
+	}
+	if options.sampler != nil {
+		sampler = options.sampler
+	}
+
+	// Always sample requests flagged for debugging through baggage
+	if options.debugBaggageKey != "" {
+		sampler = newDebugForcingSampler(options.debugBaggageKey, sampler)
 	}
 
 	// Create tracer provider with batch span processor for better performance
-	tracerProvider := sdk_trace.NewTracerProvider(
+	sdkOptions := []sdk_trace.TracerProviderOption{
 		sdk_trace.WithResource(tracerResource),
-		sdk_trace.WithSpanProcessor(sdk_trace.NewBatchSpanProcessor(tracerExporter)),
-		sdk_trace.WithSampler(sdk_trace.AlwaysSample()),
-	)
+		sdk_trace.WithSampler(sampler),
+	}
+
+	// Cap the size of spans to protect the collector
+	if cfg.MaxAttributesPerSpan > 0 || cfg.MaxEventsPerSpan > 0 || cfg.MaxLinksPerSpan > 0 {
+		spanLimits := sdk_trace.NewSpanLimits()
+		if cfg.MaxAttributesPerSpan > 0 {
+			spanLimits.AttributeCountLimit = cfg.MaxAttributesPerSpan
+		}
+		if cfg.MaxEventsPerSpan > 0 {
+			spanLimits.EventCountLimit = cfg.MaxEventsPerSpan
+		}
+		if cfg.MaxLinksPerSpan > 0 {
+			spanLimits.LinkCountLimit = cfg.MaxLinksPerSpan
+		}
+		sdkOptions = append(sdkOptions, sdk_trace.WithRawSpanLimits(spanLimits))
+	}
+
+	// Annotate spans before they reach the exporting processor
+	sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(requestIDProcessor{key: attribute.Key(cfg.RequestIDAttributeKey)}))
+	var linker *linkAttributesProcessor
+	if len(cfg.LinkAttributeKeys) > 0 {
+		linker = newLinkAttributesProcessor(cfg.LinkAttributeKeys, logger)
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(linker))
+	}
+	if cfg.MarkMalformedContext {
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(malformedContextProcessor{}))
+	}
+	var nameConvention *spanNameConventionProcessor
+	if cfg.SpanNameValidator != nil || cfg.SpanNameNormalizer != nil {
+		nameConvention = newSpanNameConventionProcessor(cfg.SpanNameValidator, cfg.SpanNameNormalizer)
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(nameConvention))
+	}
+	if cfg.MaxDistinctSpanNames > 0 {
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(newSpanNameLimitProcessor(cfg.MaxDistinctSpanNames, logger)))
+	}
+	if cfg.RecordGoroutineID {
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(goroutineIDProcessor{}))
+	}
+
+	var recorder *spanRecorder
+	if cfg.RetainSpans > 0 {
+		recorder = newSpanRecorder(cfg.RetainSpans)
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(recorder))
+	}
+
+	var latency *latencyStatsProcessor
+	if cfg.CollectLatencyStats {
+		latency = newLatencyStatsProcessor()
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(latency))
+	}
+
+	// Estimate the export queue depth for Stats when asked to; the simple processor has no queue
+	trackQueues := cfg.TrackQueueDepth && !cfg.UseSimpleProcessor
+	queueCapacity := cfg.MaxQueueSize
+	if queueCapacity <= 0 {
+		queueCapacity = sdk_trace.DefaultMaxQueueSize
+	}
+
+	// Give every exporter its own processor, filtering spans on their way to it
+	var queues []*queueTracker
+	for i, spanExporter := range spanExporters {
+		var exportProcessor sdk_trace.SpanProcessor
+		if trackQueues {
+			queue := newQueueTracker(queueCapacity)
+			queues = append(queues, queue)
+			exportProcessor = queue.processor(newExportProcessor(cfg, queue.exporter(spanExporter)))
+		} else {
+			exportProcessor = newExportProcessor(cfg, spanExporter)
+		}
+
+		// Run the pre-export processors first; processors registered later, including
+		// those of ExporterAddresses, run after them as well
+		if i == 0 && len(cfg.PreExportSpanProcessors) > 0 {
+			exportProcessor = newPreExportProcessor(exportProcessor, cfg.PreExportSpanProcessors)
+		}
+
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(exportProcessor))
+	}
+	for _, processor := range cfg.ExtraSpanProcessors {
+		sdkOptions = append(sdkOptions, sdk_trace.WithSpanProcessor(processor))
+	}
+	tracerProvider := sdk_trace.NewTracerProvider(sdkOptions...)
 
 	// Set up propagators for distributed tracing
-	textMapPropagator := propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	)
+	basePropagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+	if options.propagator != nil {
+		basePropagator = options.propagator
+	}
 
-	// Set global providers
-	otel.SetTracerProvider(tracerProvider)
-	otel.SetTextMapPropagator(textMapPropagator)
+	textMapPropagator := newCheckedPropagator(
+		basePropagator,
+		logger,
+		cfg.LogMalformedContext,
+	)
 
 	// Create tracer instance
-	tracer := otel.Tracer(cfg.ServiceName)
+	tracer := withSpanAttributes(
+		tracerProvider.Tracer(cfg.ServiceName, trace.WithInstrumentationVersion(cfg.InstrumentationVersion)),
+		options.spanAttributes,
+	)
 
-	return &TracerProvider{
+	tp := &TracerProvider{
 		tracer:          tracer,
 		provider:        tracerProvider,
-		exporter:        tracerExporter,
-		grpcConn:        grpcConn,
-		shutdownTimeout: shutdownTimeout,
-	}, nil
+		exporter:        tracerExporters[0],
+		counter:         chain.counter,
+		shutdownRetry:   chain.shutdownRetry,
+		degraders:       chain.degraders,
+		fallback:        chain.fallback,
+		limiter:         chain.limiter,
+		linker:          linker,
+		nameConvention:  nameConvention,
+		propagator:      textMapPropagator,
+		recorder:        recorder,
+		latency:         latency,
+		queues:          queues,
+		scopeSampling:   scopeSamplings,
+		spanAttributes:  options.spanAttributes,
+		shutdownTimeout: cfg.ShutdownTimeout,
+		flushTimeout:    cfg.FlushTimeout,
+		maxShutdown:     cfg.MaxShutdownTimeout,
+		startTime:       time.Now(),
+		shutdownSpan:    cfg.EmitShutdownSpan,
+	}
+
+	if cfg.HeartbeatInterval > 0 {
+		tp.startHeartbeat(cfg.HeartbeatInterval)
+	}
+
+	return tp, nil
 }
 
 // Tracer returns the underlying OpenTelemetry tracer
@@ -175,34 +1306,193 @@ func (tp *TracerProvider) Tracer() trace.Tracer {
 	return tp.tracer
 }
 
+// IsNoop reports whether the provider's tracers are noop, as with NewNoopTracerProvider,
+// so callers can skip computing span attributes that would be discarded
+func (tp *TracerProvider) IsNoop() bool {
+	return tp == nil || tp.provider == nil
+}
+
+// IsNoopTracer reports whether the tracer is a noop tracer, such as the one returned by
+// NewTracer for a nil or invalid config, so callers can skip costly span annotation
+func IsNoopTracer(t trace.Tracer) bool {
+	switch t.(type) {
+	case nil, noop.Tracer, *noop.Tracer:
+		return true
+	default:
+		return false
+	}
+}
+
+// NamedTracer returns a tracer with the given instrumentation scope name that shares
+// the provider's exporter. Tracers are cached by their instrumentation scope, i.e. the
+// name, version, schema URL and attributes, so repeated lookups on a hot path return the same instance. Spans of tracers named
+// in ScopeSamplingRatios are sampled with the ratio of their scope.
+func (tp *TracerProvider) NamedTracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	tracerConfig := trace.NewTracerConfig(opts...)
+	attributes := tracerConfig.InstrumentationAttributes()
+	key := tracerKey{
+		name:       name,
+		version:    tracerConfig.InstrumentationVersion(),
+		schemaURL:  tracerConfig.SchemaURL(),
+		attributes: attributes.Equivalent(),
+	}
+
+	if tracer, ok := tp.namedTracers.Load(key); ok {
+		return tracer.(trace.Tracer)
+	}
+
+	// Disabled providers have no SDK provider to create tracers from
+	if tp.provider == nil {
+		return noop.NewTracerProvider().Tracer(name, opts...)
+	}
+
+	var tracer trace.Tracer = tp.provider.Tracer(name, opts...)
+	if sampling, ok := tp.scopeSampling[name]; ok {
+		tracer = &scopeTracer{Tracer: tracer, sampling: sampling}
+	}
+	tracer = withSpanAttributes(tracer, tp.spanAttributes)
+
+	cached, _ := tp.namedTracers.LoadOrStore(key, tracer)
+	return cached.(trace.Tracer)
+}
+
+// ExportMode returns the current export mode of the provider, log-only as soon as
+// one of its exporters is degraded. It is always ExportModeNormal unless
+// DegradeAfterFailures is configured.
+func (tp *TracerProvider) ExportMode() ExportMode {
+	for _, degrader := range tp.degraders {
+		if degrader.Mode() == ExportModeLogOnly {
+			return ExportModeLogOnly
+		}
+	}
+
+	return ExportModeNormal
+}
+
+// ForceFlush exports all ended spans that have not been exported yet, without shutting
+// down the provider, e.g. before a short-lived process exits. Spans can still be created
+// during and after the flush. A nil context falls back to the shutdown timeout.
+func (tp *TracerProvider) ForceFlush(ctx context.Context) error {
+	if ctx == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), tp.shutdownTimeout)
+		defer cancel()
+	}
+
+	if tp.provider == nil {
+		return nil
+	}
+
+	if err := tp.provider.ForceFlush(ctx); err != nil {
+		return fmt.Errorf("failed to flush tracer provider: %w", err)
+	}
+
+	return nil
+}
+
 // Shutdown gracefully shuts down the tracer provider and all its components.
-// It ensures all spans are flushed before closing connections.
+// It ensures all spans are flushed before closing connections. The connection is
+// closed even if flushing fails, and all failures are joined in the returned error.
+// An already done context skips waiting for the flush: the connection is closed and the
+// span processors are shut down in the background.
 // This method is safe to call multiple times.
 func (tp *TracerProvider) Shutdown(ctx context.Context) error {
 	tp.shutdownOnce.Do(func() {
-		// Create context with timeout if none provided
+		grpcConn, extraConns := tp.closeConnection()
+
+		// Skip the flush on forced teardown and only release the connection
+		if ctx != nil && ctx.Err() != nil {
+			tp.stopHeartbeat()
+
+			// The SDK provider does not shut processors down with a done context, so
+			// release them in the background; with the connections closed their final
+			// exports fail fast, and MaxShutdownTimeout bounds exporters that hang
+			if tp.provider != nil {
+				go func() {
+					shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tp.maxShutdown)
+					defer cancel()
+					tp.provider.Shutdown(shutdownCtx)
+				}()
+			}
+
+			if grpcConn != nil {
+				grpcConn.Close()
+			}
+			for _, extraConn := range extraConns {
+				extraConn.Close()
+			}
+			tp.shutdownErr = fmt.Errorf("shutdown skipped flushing spans: %w", ctx.Err())
+			return
+		}
+
+		// Create context with timeout if none provided, and bound any provided one
+		var cancel context.CancelFunc
 		if ctx == nil {
-			var cancel context.CancelFunc
 			ctx, cancel = context.WithTimeout(context.Background(), tp.shutdownTimeout)
-			defer cancel()
+		} else {
+			ctx, cancel = context.WithTimeout(ctx, tp.maxShutdown)
+		}
+		defer cancel()
+
+		tp.stopHeartbeat()
+
+		// Emit the lifecycle span first so the flush below exports it
+		if tp.shutdownSpan && tp.tracer != nil {
+			tp.emitShutdownSpan(ctx)
+		}
+
+		if tp.shutdownRetry != nil {
+			tp.shutdownRetry.startShutdown()
 		}
 
 		// Shutdown tracer provider (this flushes remaining spans)
+		var errs []error
 		if tp.provider != nil {
-			if err := tp.provider.Shutdown(ctx); err != nil {
-				tp.shutdownErr = fmt.Errorf("failed to shutdown tracer provider: %w", err)
-				return
+			if err := tp.shutdownProvider(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to shutdown tracer provider: %w", err))
 			}
 		}
 
-		// Close GRPC connection
-		if tp.grpcConn != nil {
-			if err := tp.grpcConn.Close(); err != nil {
-				tp.shutdownErr = fmt.Errorf("failed to close GRPC connection: %w", err)
-				return
+		// Close GRPC connection even when the flush failed, so it does not leak
+		if grpcConn != nil {
+			if err := grpcConn.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close GRPC connection: %w", err))
 			}
 		}
+		for _, extraConn := range extraConns {
+			if err := extraConn.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close GRPC connection to %s: %w", extraConn.Target(), err))
+			}
+		}
+
+		tp.shutdownErr = errors.Join(errs...)
 	})
 
 	return tp.shutdownErr
 }
+
+// shutdownProvider shuts down the SDK provider, flushing remaining spans within FlushTimeout
+func (tp *TracerProvider) shutdownProvider(ctx context.Context) error {
+	if tp.flushTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tp.flushTimeout)
+		defer cancel()
+	}
+
+	return tp.provider.Shutdown(ctx)
+}
+
+// emitShutdownSpan records a goteletracer.shutdown span with uptime and export counters
+func (tp *TracerProvider) emitShutdownSpan(ctx context.Context) {
+	stats := tp.Stats()
+
+	_, span := tp.tracer.Start(ctx, ShutdownSpanName)
+	span.SetAttributes(
+		attribute.Int64("goteletracer.uptime_ms", time.Since(tp.startTime).Milliseconds()),
+		attribute.Int64("goteletracer.fallback_exported", stats.FallbackExported),
+		attribute.Int64("goteletracer.export_limit_dropped", stats.ExportLimitDropped),
+		attribute.Int64("goteletracer.invalid_link_attributes", stats.InvalidLinkAttributes),
+		attribute.Int64("goteletracer.malformed_contexts", stats.MalformedContexts),
+	)
+	span.End()
+}
@@ -3,13 +3,32 @@ package goteletracer
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
-// TestValidateConfig tests the configuration validation logic
+// TestValidateConfig tests the configuration validation logic through the exported ValidateConfig
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -62,384 +81,2202 @@ func TestValidateConfig(t *testing.T) {
 			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "valid config",
+			name: "unbracketed IPv6 exporter address",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317",
+				ExporterGRPCAddress: "::1:4317",
 			},
-			expectedErr: nil,
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "valid config with custom shutdown timeout",
+			name: "colon only exporter address",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317",
-				ShutdownTimeout:     15 * time.Second,
+				ExporterGRPCAddress: ":",
 			},
-			expectedErr: nil,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validateConfig(tt.config)
-
-			if tt.expectedErr != nil {
-				if err == nil {
-					t.Errorf("expected error %v, got nil", tt.expectedErr)
-					return
-				}
-				if !errors.Is(err, tt.expectedErr) {
-					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("expected no error, got %v", err)
-				}
-			}
-		})
-	}
-}
-
-// TestDefaultShutdownTimeout tests the default shutdown timeout function
-func TestDefaultShutdownTimeout(t *testing.T) {
-	timeout := defaultShutdownTimeout()
-	expected := 30 * time.Second
-
-	if timeout != expected {
-		t.Errorf("expected default shutdown timeout %v, got %v", expected, timeout)
-	}
-}
-
-// TestNewTracer tests the NewTracer function with various configurations
-func TestNewTracer(t *testing.T) {
-	tests := []struct {
-		name         string
-		config       *Config
-		expectNoop   bool
-		expectNonNil bool
-	}{
-		{
-			name:         "nil config returns noop tracer",
-			config:       nil,
-			expectNoop:   true,
-			expectNonNil: true,
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "invalid config returns noop tracer",
+			name: "missing host",
 			config: &Config{
-				ServiceName:         "",
-				ExporterGRPCAddress: "localhost:4317",
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: ":4317",
 			},
-			expectNoop:   true,
-			expectNonNil: true,
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "invalid exporter address returns noop tracer",
+			name: "missing port",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "invalid-address:99999", // High port that likely won't work
+				ExporterGRPCAddress: "localhost:",
 			},
-			expectNoop:   true,
-			expectNonNil: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tracer := NewTracer(tt.config)
-
-			if tt.expectNonNil && tracer == nil {
-				t.Error("expected non-nil tracer")
-				return
-			}
-
-			if tt.expectNoop {
-				// Check if it's a noop tracer by comparing with a known noop tracer
-				noopTracer := noop.NewTracerProvider().Tracer("")
-				_, span1 := tracer.Start(context.Background(), "test")
-				_, span2 := noopTracer.Start(context.Background(), "test")
-
-				// Both should be noop spans
-				if span1.SpanContext().IsValid() || span2.SpanContext().IsValid() {
-					// If either is valid, they might not be noop tracers
-					// This is a heuristic test since we can't directly compare types
-				}
-				span1.End()
-				span2.End()
-			}
-		})
-	}
-}
-
-// TestNewTracerProvider tests the NewTracerProvider function with realistic scenarios
-func TestNewTracerProvider(t *testing.T) {
-	tests := []struct {
-		name        string
-		config      *Config
-		expectError bool
-		errorType   error
-		description string
-	}{
-		{
-			name:        "nil config",
-			config:      nil,
-			expectError: true,
-			errorType:   ErrNilConfig,
-			description: "Nil config should be rejected",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "empty service name",
+			name: "non numeric port",
 			config: &Config{
-				ServiceName:         "",
-				ExporterGRPCAddress: "localhost:4317",
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:notaport",
 			},
-			expectError: true,
-			errorType:   ErrEmptyServiceName,
-			description: "Empty service name should be rejected",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "whitespace only service name",
+			name: "port zero",
 			config: &Config{
-				ServiceName:         "   ",
-				ExporterGRPCAddress: "localhost:4317",
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:0",
 			},
-			expectError: true,
-			errorType:   ErrEmptyServiceName,
-			description: "Whitespace-only service name should be rejected",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "empty exporter address",
+			name: "port out of range",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "",
+				ExporterGRPCAddress: "localhost:65536",
 			},
-			expectError: true,
-			errorType:   ErrEmptyExporterAddress,
-			description: "Empty exporter address should be rejected",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "invalid exporter address format",
+			name: "highest valid port",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost", // Missing port
+				ExporterGRPCAddress: "localhost:65535",
 			},
-			expectError: true,
-			errorType:   ErrInvalidExporterAddress,
-			description: "Address without port should be rejected",
+			expectedErr: nil,
 		},
 		{
-			name: "valid basic config",
+			name: "bracketed IPv6 exporter address without port",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317",
+				ExporterGRPCAddress: "[::1]:",
 			},
-			expectError: false,
-			errorType:   nil,
-			description: "Basic valid configuration should work",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "valid config with custom shutdown timeout",
+			name: "invalid additional exporter address",
 			config: &Config{
 				ServiceName:         "test-service",
 				ExporterGRPCAddress: "localhost:4317",
-				ShutdownTimeout:     15 * time.Second,
+				ExporterAddresses:   []string{"localhost"},
 			},
-			expectError: false,
-			errorType:   nil,
-			description: "Valid config with custom timeout should work",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "unreachable server address",
+			name: "additional exporter addresses with writer exporter",
 			config: &Config{
-				ServiceName:         "test-service",
-				ExporterGRPCAddress: "unreachable-host:4317",
+				ServiceName:       "test-service",
+				WriterExporter:    io.Discard,
+				ExporterAddresses: []string{"localhost:4317"},
 			},
-			expectError: false,
-			errorType:   nil,
-			description: "gRPC client creation doesn't validate connectivity immediately",
+			expectedErr: ErrWriterExporterAddresses,
 		},
 		{
-			name: "control characters in GRPC address",
+			name: "unix socket absolute path",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317\x00\x01", // Control characters that cause URL parsing errors
+				ExporterGRPCAddress: "unix:///var/run/otel.sock",
 			},
-			expectError: true,
-			errorType:   nil, // Don't check specific error type as it comes from gRPC
-			description: "Control characters should cause gRPC client creation to fail",
+			expectedErr: nil,
 		},
 		{
-			name: "IPv6 localhost",
+			name: "unix socket relative path",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "[::1]:4317",
+				ExporterGRPCAddress: "unix:otel.sock",
 			},
-			expectError: false,
-			errorType:   nil,
-			description: "IPv6 addresses should be supported",
+			expectedErr: nil,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			provider, err := NewTracerProvider(tt.config)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("expected error but got nil")
-					return
-				}
-
-				// Check specific error type if specified
-				if tt.errorType != nil && !errors.Is(err, tt.errorType) {
-					t.Errorf("expected error type %v, got %v", tt.errorType, err)
-				}
-
-				// Provider should be nil on error
-				if provider != nil {
-					t.Errorf("expected nil provider on error")
-				}
-
-				t.Logf("Got expected error: %v", err)
-				return
-			}
-
-			// Success case validations
-			if err != nil {
-				t.Errorf("expected no error, got %v", err)
-				return
-			}
-
-			if provider == nil {
-				t.Errorf("expected non-nil provider")
-				return
-			}
-
-			// Test provider functionality
-			tracer := provider.Tracer()
-			if tracer == nil {
-				t.Errorf("expected non-nil tracer")
-				return
-			}
-
-			// Test basic span creation
-			_, span := tracer.Start(context.Background(), "test-span")
-			if span != nil {
-				span.End()
-			}
-
-			// Clean up
-			shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-			defer cancel()
-
-			if shutdownErr := provider.Shutdown(shutdownCtx); shutdownErr != nil {
-				t.Logf("Shutdown error (may be expected for unreachable hosts): %v", shutdownErr)
-			}
-		})
-	}
-}
-
-// TestTracerProviderShutdown tests the shutdown functionality
-func TestTracerProviderShutdown(t *testing.T) {
-	tests := []struct {
-		name        string
-		config      *Config
-		testContext func() context.Context
-		description string
-	}{
 		{
-			name: "shutdown with valid context",
+			name: "unix socket without path",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317",
-				ShutdownTimeout:     5 * time.Second,
-			},
-			testContext: func() context.Context {
-				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-				defer cancel()
-				return ctx
+				ExporterGRPCAddress: "unix://",
 			},
-			description: "Normal shutdown with provided context",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "shutdown with nil context",
+			name: "unix socket with authority",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317",
-				ShutdownTimeout:     1 * time.Second,
-			},
-			testContext: func() context.Context {
-				return nil // This should trigger the nil context path
+				ExporterGRPCAddress: "unix://var/run/otel.sock",
 			},
-			description: "Shutdown with nil context should use default timeout",
+			expectedErr: ErrInvalidExporterAddress,
 		},
 		{
-			name: "shutdown multiple times",
+			name: "bracketed IPv6 exporter address",
 			config: &Config{
 				ServiceName:         "test-service",
-				ExporterGRPCAddress: "localhost:4317",
-				ShutdownTimeout:     2 * time.Second,
+				ExporterGRPCAddress: "[::1]:4317",
 			},
-			testContext: func() context.Context {
-				return context.Background()
+			expectedErr: nil,
+		},
+		{
+			name: "scheme based exporter target",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "dns:///localhost:4317",
 			},
-			description: "Multiple shutdown calls should be safe",
+			expectedErr: nil,
 		},
 		{
-			name: "shutdown with expired context",
+			name: "writer exporter without GRPC address",
+			config: &Config{
+				ServiceName:    "test-service",
+				WriterExporter: io.Discard,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "valid config",
 			config: &Config{
 				ServiceName:         "test-service",
 				ExporterGRPCAddress: "localhost:4317",
-				ShutdownTimeout:     3 * time.Second,
 			},
-			testContext: func() context.Context {
-				ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
-				time.Sleep(1 * time.Millisecond) // Ensure context expires
-				defer cancel()
-				return ctx
+			expectedErr: nil,
+		},
+		{
+			name: "valid config with custom shutdown timeout",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     15 * time.Second,
 			},
-			description: "Shutdown with expired context should handle timeout",
+			expectedErr: nil,
+		},
+		{
+			name: "valid span kind sampling ratios",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SpanKindSamplingRatios: map[trace.SpanKind]float64{
+					trace.SpanKindServer:   1,
+					trace.SpanKindInternal: 0.01,
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "custom resource attributes",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ResourceAttributes:  map[string]string{"service.version": "1.2.3"},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "reserved resource attribute",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ResourceAttributes:  map[string]string{"service.name": "other"},
+			},
+			expectedErr: ErrReservedResourceKey,
+		},
+		{
+			name: "batch size within queue size",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				MaxQueueSize:        100,
+				MaxExportBatchSize:  100,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "batch size above queue size",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				MaxQueueSize:        100,
+				MaxExportBatchSize:  101,
+			},
+			expectedErr: ErrInvalidBatchSize,
+		},
+		{
+			name: "batch size above default queue size",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				MaxExportBatchSize:  sdk_trace.DefaultMaxQueueSize + 1,
+			},
+			expectedErr: ErrInvalidBatchSize,
+		},
+		{
+			name: "gzip compression",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				Compression:         CompressionGzip,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "no compression",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				Compression:         CompressionNone,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "unknown compression",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				Compression:         "zstd",
+			},
+			expectedErr: ErrInvalidCompression,
+		},
+		{
+			name: "sampling ratio zero keeps always sampling",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       0,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "sampling ratio one",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       1,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "sampling ratio half",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       0.5,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "sampling ratio above one",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       1.1,
+			},
+			expectedErr: ErrInvalidSamplingRatio,
+		},
+		{
+			name: "negative sampling ratio",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       -0.5,
+			},
+			expectedErr: ErrInvalidSamplingRatio,
+		},
+		{
+			name: "record all sample ratio above one",
+			config: &Config{
+				ServiceName:          "test-service",
+				ExporterGRPCAddress:  "localhost:4317",
+				RecordAllSampleRatio: 2,
+			},
+			expectedErr: ErrInvalidSamplingRatio,
+		},
+		{
+			name: "span kind sampling ratio above one",
+			config: &Config{
+				ServiceName:            "test-service",
+				ExporterGRPCAddress:    "localhost:4317",
+				SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindServer: 1.5},
+			},
+			expectedErr: ErrInvalidSamplingRatio,
+		},
+		{
+			name: "negative span kind sampling ratio",
+			config: &Config{
+				ServiceName:            "test-service",
+				ExporterGRPCAddress:    "localhost:4317",
+				SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindInternal: -0.1},
+			},
+			expectedErr: ErrInvalidSamplingRatio,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			provider, err := NewTracerProvider(tt.config)
+			err := ValidateConfig(tt.config)
+
+			if tt.expectedErr != nil {
+				if err == nil {
+					t.Errorf("expected error %v, got nil", tt.expectedErr)
+					return
+				}
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestDefaultShutdownTimeout tests the default shutdown timeout function
+func TestDefaultShutdownTimeout(t *testing.T) {
+	timeout := defaultShutdownTimeout()
+	expected := 30 * time.Second
+
+	if timeout != expected {
+		t.Errorf("expected default shutdown timeout %v, got %v", expected, timeout)
+	}
+}
+
+// TestSetDefaultShutdownTimeout tests that configs without ShutdownTimeout adopt the changed default
+// and that non-positive defaults are rejected
+func TestSetDefaultShutdownTimeout(t *testing.T) {
+	previous := defaultShutdownTimeout()
+	t.Cleanup(func() { SetDefaultShutdownTimeout(previous) })
+
+	if err := SetDefaultShutdownTimeout(5 * time.Second); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		if err := SetDefaultShutdownTimeout(timeout); !errors.Is(err, ErrInvalidShutdownTimeout) {
+			t.Errorf("expected ErrInvalidShutdownTimeout for %v, got %v", timeout, err)
+		}
+	}
+
+	provider, _ := newTestProvider(t, nil)
+	if provider.shutdownTimeout != 5*time.Second {
+		t.Errorf("expected shutdown timeout %v, got %v", 5*time.Second, provider.shutdownTimeout)
+	}
+
+	provider, _ = newTestProvider(t, &Config{
+		ServiceName:     "test-service",
+		ShutdownTimeout: time.Second,
+	})
+	if provider.shutdownTimeout != time.Second {
+		t.Errorf("expected the configured shutdown timeout %v, got %v", time.Second, provider.shutdownTimeout)
+	}
+}
+
+// TestMaxShutdownTimeout tests that ShutdownTimeout is capped by MaxShutdownTimeout
+func TestMaxShutdownTimeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          *Config
+		expectedTimeout time.Duration
+	}{
+		{
+			name:            "default cap",
+			config:          &Config{ShutdownTimeout: 24 * time.Hour},
+			expectedTimeout: defaultMaxShutdownTimeout(),
+		},
+		{
+			name:            "custom cap",
+			config:          &Config{ShutdownTimeout: time.Minute, MaxShutdownTimeout: 10 * time.Second},
+			expectedTimeout: 10 * time.Second,
+		},
+		{
+			name:            "below cap",
+			config:          &Config{ShutdownTimeout: 5 * time.Second},
+			expectedTimeout: 5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resolved := resolveConfig(tt.config); resolved.ShutdownTimeout != tt.expectedTimeout {
+				t.Errorf("expected shutdown timeout %v, got %v", tt.expectedTimeout, resolved.ShutdownTimeout)
+			}
+		})
+	}
+}
+
+// hangingExporter is a span exporter whose Shutdown blocks until its context is done
+type hangingExporter struct{}
+
+// ExportSpans does nothing
+func (hangingExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	return nil
+}
+
+// Shutdown blocks until the context is done
+func (hangingExporter) Shutdown(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// deadlineExporter is a span exporter reporting the deadline of its Shutdown context
+type deadlineExporter struct {
+	deadlines chan time.Time
+}
+
+// ExportSpans does nothing
+func (deadlineExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	return nil
+}
+
+// Shutdown sends the context deadline, or the zero time without one
+func (e deadlineExporter) Shutdown(ctx context.Context) error {
+	deadline, _ := ctx.Deadline()
+	e.deadlines <- deadline
+	return nil
+}
+
+// TestShutdownRetries tests that failed exports of the final flush are retried on Shutdown only
+func TestShutdownRetries(t *testing.T) {
+	transientErr := errors.New("collector unavailable")
+
+	tests := []struct {
+		name             string
+		retries          int
+		expectedExported int
+		expectedCalls    int
+	}{
+		{name: "succeeds on retry", retries: 2, expectedExported: 1, expectedCalls: 3},
+		{name: "without retries", retries: 0, expectedExported: 0, expectedCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &stubExporter{errs: []error{transientErr, transientErr}}
+			provider, err := newTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownRetries:     tt.retries,
+			}, exporter)
 			if err != nil {
-				t.Logf("Provider creation failed (which is valid for unreachable hosts): %v", err)
-				return
+				t.Fatalf("failed to create provider: %v", err)
 			}
 
-			if provider == nil {
-				t.Skip("Provider is nil, skipping shutdown test")
-				return
+			// Failures before shutdown are not retried
+			_, span := provider.Tracer().Start(context.Background(), "before-shutdown")
+			span.End()
+			if err := provider.ForceFlush(context.Background()); !errors.Is(err, transientErr) {
+				t.Errorf("expected the flush to fail without retries, got %v", err)
 			}
 
-			// Test the shutdown functionality
-			ctx := tt.testContext()
+			_, span = provider.Tracer().Start(context.Background(), "operation")
+			span.End()
 
-			if tt.name == "shutdown multiple times" {
-				// Test multiple shutdown calls
-				err1 := provider.Shutdown(ctx)
-				err2 := provider.Shutdown(ctx)
-				err3 := provider.Shutdown(context.Background()) // Different context
+			// The batch processor reports export failures to the otel error handler
+			if err := provider.Shutdown(context.Background()); err != nil {
+				t.Errorf("expected no shutdown error, got %v", err)
+			}
 
-				// All should return the same error (if any) due to sync.Once
-				if err1 != err2 || err2 != err3 {
-					t.Errorf("shutdown returned different errors on multiple calls: %v, %v, %v", err1, err2, err3)
-				}
-				t.Logf("Multiple shutdown completed with consistent error: %v", err1)
-			} else {
-				// Single shutdown test
-				shutdownErr := provider.Shutdown(ctx)
-				t.Logf("Shutdown completed with result: %v", shutdownErr)
+			exporter.mu.Lock()
+			defer exporter.mu.Unlock()
+			if exporter.exported != tt.expectedExported {
+				t.Errorf("expected %d exported spans, got %d", tt.expectedExported, exporter.exported)
+			}
+			if exporter.calls != tt.expectedCalls {
+				t.Errorf("expected %d export calls, got %d", tt.expectedCalls, exporter.calls)
+			}
+		})
+	}
+}
 
-				// Test that second shutdown returns same error
-				shutdownErr2 := provider.Shutdown(ctx)
-				if shutdownErr != shutdownErr2 {
-					t.Errorf("second shutdown returned different error: %v vs %v", shutdownErr, shutdownErr2)
-				}
+// TestFlushTimeout tests that the provider shutdown is bounded by FlushTimeout
+func TestFlushTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		flush       time.Duration
+		maxDeadline time.Duration
+	}{
+		{name: "flush bounded by FlushTimeout", flush: 200 * time.Millisecond, maxDeadline: 200 * time.Millisecond},
+		{name: "flush uses the shutdown budget", flush: 0, maxDeadline: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := deadlineExporter{deadlines: make(chan time.Time, 1)}
+			provider, err := newTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     10 * time.Second,
+				FlushTimeout:        tt.flush,
+			}, exporter)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			// A nil context falls back to ShutdownTimeout
+			var nilCtx context.Context
+			start := time.Now()
+			if err := provider.Shutdown(nilCtx); err != nil {
+				t.Fatalf("expected no shutdown error, got %v", err)
+			}
+
+			deadline := <-exporter.deadlines
+			if deadline.IsZero() {
+				t.Fatal("expected a shutdown deadline")
+			}
+			if budget := deadline.Sub(start); budget > tt.maxDeadline+100*time.Millisecond || budget < tt.maxDeadline/2 {
+				t.Errorf("expected a flush deadline of about %v, got %v", tt.maxDeadline, budget)
+			}
+		})
+	}
+}
+
+// TestShutdownBoundedContext tests that Shutdown with an unbounded context still terminates
+func TestShutdownBoundedContext(t *testing.T) {
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		MaxShutdownTimeout:  50 * time.Millisecond,
+	}, hangingExporter{})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- provider.Shutdown(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Shutdown to be bounded by MaxShutdownTimeout")
+	}
+}
+
+// TestNewTracer tests the NewTracer function with various configurations
+func TestNewTracer(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *Config
+		expectNoop   bool
+		expectNonNil bool
+	}{
+		{
+			name:         "nil config returns noop tracer",
+			config:       nil,
+			expectNoop:   true,
+			expectNonNil: true,
+		},
+		{
+			name: "invalid config returns noop tracer",
+			config: &Config{
+				ServiceName:         "",
+				ExporterGRPCAddress: "localhost:4317",
+			},
+			expectNoop:   true,
+			expectNonNil: true,
+		},
+		{
+			name: "invalid exporter address returns noop tracer",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "invalid-address:99999", // High port that likely won't work
+			},
+			expectNoop:   true,
+			expectNonNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := NewTracer(tt.config)
+
+			if tt.expectNonNil && tracer == nil {
+				t.Error("expected non-nil tracer")
+				return
+			}
+
+			if tt.expectNoop {
+				// Check if it's a noop tracer by comparing with a known noop tracer
+				noopTracer := noop.NewTracerProvider().Tracer("")
+				_, span1 := tracer.Start(context.Background(), "test")
+				_, span2 := noopTracer.Start(context.Background(), "test")
+
+				// Both should be noop spans
+				if span1.SpanContext().IsValid() || span2.SpanContext().IsValid() {
+					// If either is valid, they might not be noop tracers
+					// This is a heuristic test since we can't directly compare types
+				}
+				span1.End()
+				span2.End()
+			}
+		})
+	}
+}
+
+// TestNewTracerOrError tests that the error behind a noop fallback is returned
+func TestNewTracerOrError(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *Config
+		expectedErr error
+	}{
+		{
+			name:        "nil config",
+			config:      nil,
+			expectedErr: ErrNilConfig,
+		},
+		{
+			name: "invalid exporter address",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost",
+			},
+			expectedErr: ErrInvalidExporterAddress,
+		},
+		{
+			name: "valid config",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+			},
+			expectedErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer, err := NewTracerOrError(tt.config)
+			if tracer == nil {
+				t.Fatal("expected a usable tracer")
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			_, span := tracer.Start(context.Background(), "operation")
+			defer span.End()
+
+			if recording := span.IsRecording(); recording != (tt.expectedErr == nil) {
+				t.Errorf("expected recording=%v, got %v", tt.expectedErr == nil, recording)
+			}
+		})
+	}
+}
+
+// TestNewTracerProvider tests the NewTracerProvider function with realistic scenarios
+func TestNewTracerProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *Config
+		expectError bool
+		errorType   error
+		description string
+	}{
+		{
+			name:        "nil config",
+			config:      nil,
+			expectError: true,
+			errorType:   ErrNilConfig,
+			description: "Nil config should be rejected",
+		},
+		{
+			name: "empty service name",
+			config: &Config{
+				ServiceName:         "",
+				ExporterGRPCAddress: "localhost:4317",
+			},
+			expectError: true,
+			errorType:   ErrEmptyServiceName,
+			description: "Empty service name should be rejected",
+		},
+		{
+			name: "whitespace only service name",
+			config: &Config{
+				ServiceName:         "   ",
+				ExporterGRPCAddress: "localhost:4317",
+			},
+			expectError: true,
+			errorType:   ErrEmptyServiceName,
+			description: "Whitespace-only service name should be rejected",
+		},
+		{
+			name: "empty exporter address",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "",
+			},
+			expectError: true,
+			errorType:   ErrEmptyExporterAddress,
+			description: "Empty exporter address should be rejected",
+		},
+		{
+			name: "invalid exporter address format",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost", // Missing port
+			},
+			expectError: true,
+			errorType:   ErrInvalidExporterAddress,
+			description: "Address without port should be rejected",
+		},
+		{
+			name: "valid basic config",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+			},
+			expectError: false,
+			errorType:   nil,
+			description: "Basic valid configuration should work",
+		},
+		{
+			name: "valid config with custom shutdown timeout",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     15 * time.Second,
+			},
+			expectError: false,
+			errorType:   nil,
+			description: "Valid config with custom timeout should work",
+		},
+		{
+			name: "unreachable server address",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "unreachable-host:4317",
+			},
+			expectError: false,
+			errorType:   nil,
+			description: "gRPC client creation doesn't validate connectivity immediately",
+		},
+		{
+			name: "control characters in GRPC address",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317\x00\x01", // Control characters in the port
+			},
+			expectError: true,
+			errorType:   ErrInvalidExporterAddress,
+			description: "Control characters in the port should fail validation",
+		},
+		{
+			name: "IPv6 localhost",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "[::1]:4317",
+			},
+			expectError: false,
+			errorType:   nil,
+			description: "IPv6 addresses should be supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewTracerProvider(tt.config)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+					return
+				}
+
+				// Check specific error type if specified
+				if tt.errorType != nil && !errors.Is(err, tt.errorType) {
+					t.Errorf("expected error type %v, got %v", tt.errorType, err)
+				}
+
+				// Provider should be nil on error
+				if provider != nil {
+					t.Errorf("expected nil provider on error")
+				}
+
+				t.Logf("Got expected error: %v", err)
+				return
+			}
+
+			// Success case validations
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+
+			if provider == nil {
+				t.Errorf("expected non-nil provider")
+				return
+			}
+
+			// Test provider functionality
+			tracer := provider.Tracer()
+			if tracer == nil {
+				t.Errorf("expected non-nil tracer")
+				return
+			}
+
+			// Test basic span creation
+			_, span := tracer.Start(context.Background(), "test-span")
+			if span != nil {
+				span.End()
+			}
+
+			// Clean up
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			if shutdownErr := provider.Shutdown(shutdownCtx); shutdownErr != nil {
+				t.Logf("Shutdown error (may be expected for unreachable hosts): %v", shutdownErr)
+			}
+		})
+	}
+}
+
+// TestForceFlush tests that ForceFlush exports ended spans and keeps the provider usable
+func TestForceFlush(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				_, span := provider.Tracer().Start(context.Background(), "concurrent")
+				span.End()
+			}
+		}()
+	}
+
+	// A nil context falls back to the shutdown timeout
+	var nilCtx context.Context
+	if err := provider.ForceFlush(nilCtx); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+	wg.Wait()
+
+	_, span := provider.Tracer().Start(context.Background(), "after flush")
+	span.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if spans := exporter.GetSpans(); len(spans) != 101 {
+		t.Errorf("expected 101 exported spans, got %d", len(spans))
+	}
+}
+
+// TestShutdownJoinsErrors tests that the connection is closed and all failures surface
+func TestShutdownJoinsErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		closeConnFirst bool
+		expectedErrs   []string
+	}{
+		{
+			name:         "connection closed after flush failure",
+			expectedErrs: []string{"failed to shutdown tracer provider"},
+		},
+		{
+			name:           "both failures joined",
+			closeConnFirst: true,
+			expectedErrs:   []string{"failed to shutdown tracer provider", "failed to close GRPC connection"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The hanging exporter makes the provider shutdown fail on the deadline
+			provider, err := newTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				MaxShutdownTimeout:  50 * time.Millisecond,
+			}, hangingExporter{})
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			conn, err := grpc.NewClient("localhost:4317", grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatalf("failed to create connection: %v", err)
+			}
+			if tt.closeConnFirst {
+				conn.Close()
+			}
+			provider.grpcConn = conn
+
+			err = provider.Shutdown(context.Background())
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the provider shutdown error, got %v", err)
+			}
+			for _, expected := range tt.expectedErrs {
+				if err == nil || !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error to mention %q, got %v", expected, err)
+				}
+			}
+
+			if state := conn.GetState(); state != connectivity.Shutdown {
+				t.Errorf("expected the connection to be closed, got %s", state)
+			}
+		})
+	}
+}
+
+// TestShutdownCancelledContext tests that a done context returns promptly and closes the connection
+func TestShutdownCancelledContext(t *testing.T) {
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+	}, hangingExporter{})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	conn, err := grpc.NewClient("localhost:4317", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to create connection: %v", err)
+	}
+	provider.grpcConn = conn
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	err = provider.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected a prompt return, took %v", elapsed)
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+	if state := conn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("expected the connection to be closed, got %s", state)
+	}
+}
+
+// TestShutdownCancelledContextReleasesProcessors tests that a done context still shuts down
+// the span processors, so a forced teardown does not leak them
+func TestShutdownCancelledContextReleasesProcessors(t *testing.T) {
+	extra := &countingProcessor{}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		ExtraSpanProcessors: []sdk_trace.SpanProcessor{extra},
+		DisableGlobal:       true,
+	}, &stubExporter{})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := provider.Shutdown(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	shutdowns := func() int {
+		extra.mu.Lock()
+		defer extra.mu.Unlock()
+
+		return extra.shutdowns
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for shutdowns() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := shutdowns(); got != 1 {
+		t.Errorf("expected the span processors to be shut down once, got %d", got)
+	}
+}
+
+// TestTracerProviderShutdown tests the shutdown functionality
+func TestTracerProviderShutdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *Config
+		testContext func() context.Context
+		description string
+	}{
+		{
+			name: "shutdown with valid context",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     5 * time.Second,
+			},
+			testContext: func() context.Context {
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				defer cancel()
+				return ctx
+			},
+			description: "Normal shutdown with provided context",
+		},
+		{
+			name: "shutdown with nil context",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     1 * time.Second,
+			},
+			testContext: func() context.Context {
+				return nil // This should trigger the nil context path
+			},
+			description: "Shutdown with nil context should use default timeout",
+		},
+		{
+			name: "shutdown multiple times",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     2 * time.Second,
+			},
+			testContext: func() context.Context {
+				return context.Background()
+			},
+			description: "Multiple shutdown calls should be safe",
+		},
+		{
+			name: "shutdown with expired context",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ShutdownTimeout:     3 * time.Second,
+			},
+			testContext: func() context.Context {
+				ctx, cancel := context.WithTimeout(context.Background(), 1*time.Nanosecond)
+				time.Sleep(1 * time.Millisecond) // Ensure context expires
+				defer cancel()
+				return ctx
+			},
+			description: "Shutdown with expired context should handle timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewTracerProvider(tt.config)
+			if err != nil {
+				t.Logf("Provider creation failed (which is valid for unreachable hosts): %v", err)
+				return
+			}
+
+			if provider == nil {
+				t.Skip("Provider is nil, skipping shutdown test")
+				return
+			}
+
+			// Test the shutdown functionality
+			ctx := tt.testContext()
+
+			if tt.name == "shutdown multiple times" {
+				// Test multiple shutdown calls
+				err1 := provider.Shutdown(ctx)
+				err2 := provider.Shutdown(ctx)
+				err3 := provider.Shutdown(context.Background()) // Different context
+
+				// All should return the same error (if any) due to sync.Once
+				if err1 != err2 || err2 != err3 {
+					t.Errorf("shutdown returned different errors on multiple calls: %v, %v, %v", err1, err2, err3)
+				}
+				t.Logf("Multiple shutdown completed with consistent error: %v", err1)
+			} else {
+				// Single shutdown test
+				shutdownErr := provider.Shutdown(ctx)
+				t.Logf("Shutdown completed with result: %v", shutdownErr)
+
+				// Test that second shutdown returns same error
+				shutdownErr2 := provider.Shutdown(ctx)
+				if shutdownErr != shutdownErr2 {
+					t.Errorf("second shutdown returned different error: %v vs %v", shutdownErr, shutdownErr2)
+				}
+			}
+		})
+	}
+}
+
+// newTestProvider creates a TracerProvider backed by an in-memory exporter
+func newTestProvider(t *testing.T, cfg *Config) (*TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	if cfg == nil {
+		cfg = &Config{
+			ServiceName:         "test-service",
+			ExporterGRPCAddress: "localhost:4317",
+		}
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := newTracerProvider(context.Background(), cfg, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+
+	return provider, exporter
+}
+
+// TestNamedTracerCaching tests that named tracers are cached by their instrumentation scope
+func TestNamedTracerCaching(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	first := provider.NamedTracer("library")
+	second := provider.NamedTracer("library")
+	if first != second {
+		t.Error("expected repeated lookups to return the same tracer")
+	}
+
+	versioned := provider.NamedTracer("library", trace.WithInstrumentationVersion("1.0.0"))
+	if versioned == first {
+		t.Error("expected a different tracer for a different version")
+	}
+
+	if again := provider.NamedTracer("library", trace.WithInstrumentationVersion("1.0.0")); again != versioned {
+		t.Error("expected repeated versioned lookups to return the same tracer")
+	}
+
+	if other := provider.NamedTracer("other-library"); other == first {
+		t.Error("expected a different tracer for a different name")
+	}
+
+	schema := provider.NamedTracer("library", trace.WithSchemaURL("https://opentelemetry.io/schemas/1.27.0"))
+	if schema == first {
+		t.Error("expected a different tracer for a different schema URL")
+	}
+
+	attributed := provider.NamedTracer("library", trace.WithInstrumentationAttributes(attribute.String("module", "a")))
+	if attributed == first {
+		t.Error("expected a different tracer for different instrumentation attributes")
+	}
+	if again := provider.NamedTracer("library", trace.WithInstrumentationAttributes(attribute.String("module", "a"))); again != attributed {
+		t.Error("expected repeated lookups with the same attributes to return the same tracer")
+	}
+	if other := provider.NamedTracer("library", trace.WithInstrumentationAttributes(attribute.String("module", "b"))); other == attributed {
+		t.Error("expected a different tracer for different attribute values")
+	}
+}
+
+// TestNamedTracerScopes tests that named tracers of several logical services share one
+// exporter and tag their spans with their own instrumentation scope
+func TestNamedTracerScopes(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"billing", "shipping"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, span := provider.NamedTracer(name, trace.WithInstrumentationVersion("1.0.0")).Start(context.Background(), name+"-operation")
+			span.End()
+		}()
+	}
+	wg.Wait()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans on the shared exporter, got %d", len(spans))
+	}
+
+	for _, span := range spans {
+		scope := span.InstrumentationScope
+		if span.Name != scope.Name+"-operation" {
+			t.Errorf("expected span %q to be tagged with its tracer scope, got %q", span.Name, scope.Name)
+		}
+		if scope.Version != "1.0.0" {
+			t.Errorf("expected scope version %q, got %q", "1.0.0", scope.Version)
+		}
+		if value, _ := span.Resource.Set().Value(semconv.ServiceNameKey); value.AsString() != "test-service" {
+			t.Errorf("expected the provider service name on %q, got %q", span.Name, value.AsString())
+		}
+	}
+}
+
+// TestInstrumentationVersion tests that spans of the provider's tracer carry the configured scope version
+func TestInstrumentationVersion(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:            "test-service",
+		InstrumentationVersion: "2.3.0",
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	scope := spans[0].InstrumentationScope
+	if scope.Name != "test-service" {
+		t.Errorf("expected scope name %q, got %q", "test-service", scope.Name)
+	}
+	if scope.Version != "2.3.0" {
+		t.Errorf("expected scope version %q, got %q", "2.3.0", scope.Version)
+	}
+}
+
+// TestDeploymentTimestamp tests the opt-in deployment.timestamp resource attribute
+func TestDeploymentTimestamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		expectKey bool
+	}{
+		{name: "disabled by default", enabled: false, expectKey: false},
+		{name: "enabled", enabled: true, expectKey: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:               "test-service",
+				ExporterGRPCAddress:       "localhost:4317",
+				RecordDeploymentTimestamp: tt.enabled,
+			})
+
+			_, span := provider.Tracer().Start(context.Background(), "test-span")
+			span.End()
+			if err := provider.provider.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("expected no flush error, got %v", err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			value, ok := spans[0].Resource.Set().Value(DeploymentTimestampKey)
+			if ok != tt.expectKey {
+				t.Fatalf("expected deployment timestamp present=%v, got %v", tt.expectKey, ok)
+			}
+			if ok {
+				if _, err := time.Parse(time.RFC3339, value.AsString()); err != nil {
+					t.Errorf("expected RFC3339 timestamp, got %q", value.AsString())
+				}
+			}
+		})
+	}
+}
+
+// TestResourceAttributes tests that configured resource attributes appear on exported spans
+func TestResourceAttributes(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		ResourceAttributes: map[string]string{
+			"service.version":        "1.2.3",
+			"deployment.environment": "staging",
+		},
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	resource := spans[0].Resource.Set()
+	expected := map[attribute.Key]string{
+		"service.name":           "test-service",
+		"service.version":        "1.2.3",
+		"deployment.environment": "staging",
+	}
+	for key, value := range expected {
+		if got, ok := resource.Value(key); !ok || got.AsString() != value {
+			t.Errorf("expected resource attribute %s=%q, got %v", key, value, got)
+		}
+	}
+}
+
+// TestServiceVersionAndEnvironment tests that the shortcut fields are only added when set
+func TestServiceVersionAndEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		environment string
+	}{
+		{name: "both unset"},
+		{name: "version only", version: "1.4.2"},
+		{name: "environment only", environment: "production"},
+		{name: "both set", version: "1.4.2", environment: "production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ServiceVersion:      tt.version,
+				Environment:         tt.environment,
+			})
+
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			set := spans[0].Resource.Set()
+
+			expected := map[attribute.Key]string{
+				semconv.ServiceVersionKey:            tt.version,
+				semconv.DeploymentEnvironmentNameKey: tt.environment,
+			}
+			for key, value := range expected {
+				got, ok := set.Value(key)
+				if ok != (value != "") || got.AsString() != value {
+					t.Errorf("expected %s=%q, got %v (present=%v)", key, value, got.AsString(), ok)
+				}
+			}
+		})
+	}
+}
+
+// TestServiceInstanceID tests the explicit, generated and missing service.instance.id resource attribute
+func TestServiceInstanceID(t *testing.T) {
+	tests := []struct {
+		name       string
+		instanceID string
+		auto       bool
+		expectUUID bool
+	}{
+		{name: "neither"},
+		{name: "explicit", instanceID: "orders-7d9f8-abcde"},
+		{name: "explicit takes precedence", instanceID: "orders-7d9f8-abcde", auto: true},
+		{name: "auto generated", auto: true, expectUUID: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				ServiceInstanceID:   tt.instanceID,
+				AutoInstanceID:      tt.auto,
+			})
+
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			value, ok := spans[0].Resource.Set().Value(semconv.ServiceInstanceIDKey)
+			switch {
+			case tt.expectUUID:
+				if _, err := uuid.Parse(value.AsString()); err != nil {
+					t.Errorf("expected a generated UUID, got %q", value.AsString())
+				}
+			case tt.instanceID != "":
+				if value.AsString() != tt.instanceID {
+					t.Errorf("expected instance ID %q, got %q", tt.instanceID, value.AsString())
+				}
+			default:
+				if ok {
+					t.Errorf("expected no instance ID, got %q", value.AsString())
+				}
+			}
+		})
+	}
+}
+
+// TestResourceFallback tests that a failing resource creation degrades to a fallback
+// resource unless StrictResource is set
+func TestResourceFallback(t *testing.T) {
+	detectorErr := errors.New("detector failed")
+	partial := resource.NewSchemaless(
+		semconv.ServiceNameKey.String("test-service"),
+		attribute.String("host.name", "partial"),
+	)
+
+	tests := []struct {
+		name             string
+		strict           bool
+		resource         *resource.Resource
+		expectErr        bool
+		expectedHostName string
+	}{
+		{name: "fallback to service name", resource: nil},
+		{name: "keeps partial resource", resource: partial, expectedHostName: "partial"},
+		{name: "strict resource fails", strict: true, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := newResource
+			newResource = func(context.Context, ...resource.Option) (*resource.Resource, error) {
+				return tt.resource, detectorErr
+			}
+			t.Cleanup(func() { newResource = original })
+
+			logger := &captureLogger{}
+			exporter := tracetest.NewInMemoryExporter()
+			provider, err := newTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				Logger:              logger,
+				StrictResource:      tt.strict,
+			}, exporter)
+
+			if tt.expectErr {
+				if !errors.Is(err, detectorErr) {
+					t.Fatalf("expected the resource error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			defer provider.Shutdown(context.Background())
+
+			if logger.count() != 1 {
+				t.Errorf("expected the resource failure to be logged once, got %v", logger.messages)
+			}
+
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			set := spans[0].Resource.Set()
+			if value, _ := set.Value(semconv.ServiceNameKey); value.AsString() != "test-service" {
+				t.Errorf("expected service name on the fallback resource, got %v", set)
+			}
+			if value, _ := set.Value("host.name"); value.AsString() != tt.expectedHostName {
+				t.Errorf("expected host name %q, got %q", tt.expectedHostName, value.AsString())
+			}
+		})
+	}
+}
+
+// TestProviderErrorStage tests that creation failures report the stage they happened at
+func TestProviderErrorStage(t *testing.T) {
+	causeErr := errors.New("induced failure")
+
+	tests := []struct {
+		name          string
+		induce        func(t *testing.T)
+		opts          []Option
+		strict        bool
+		expectedStage ProviderStage
+	}{
+		{
+			name: "resource",
+			induce: func(t *testing.T) {
+				original := newResource
+				newResource = func(context.Context, ...resource.Option) (*resource.Resource, error) {
+					return nil, causeErr
+				}
+				t.Cleanup(func() { newResource = original })
+			},
+			strict:        true,
+			expectedStage: StageResource,
+		},
+		{
+			name:          "grpc",
+			induce:        func(t *testing.T) {},
+			opts:          []Option{WithCACertFile("testdata/missing-ca.pem")},
+			expectedStage: StageGRPC,
+		},
+		{
+			name: "exporter",
+			induce: func(t *testing.T) {
+				original := newOTLPExporter
+				newOTLPExporter = func(context.Context, ...otlptracegrpc.Option) (*otlptrace.Exporter, error) {
+					return nil, causeErr
+				}
+				t.Cleanup(func() { newOTLPExporter = original })
+			},
+			expectedStage: StageExporter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.induce(t)
+
+			provider, err := NewTracerProviderWithOptions(&Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				StrictResource:      tt.strict,
+				DisableGlobal:       true,
+			}, tt.opts...)
+			if err == nil {
+				provider.Shutdown(context.Background())
+				t.Fatal("expected an error")
+			}
+
+			var providerErr *ProviderError
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("expected a ProviderError, got %T: %v", err, err)
+			}
+			if providerErr.Stage != tt.expectedStage {
+				t.Errorf("expected stage %q, got %q", tt.expectedStage, providerErr.Stage)
+			}
+		})
+	}
+}
+
+// TestExporterTimeout tests that a slow exporter setup is reported as ErrExporterTimeout
+func TestExporterTimeout(t *testing.T) {
+	original := newOTLPExporter
+	newOTLPExporter = func(ctx context.Context, _ ...otlptracegrpc.Option) (*otlptrace.Exporter, error) {
+		// Simulate a collector slower than the setup deadline
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	t.Cleanup(func() { newOTLPExporter = original })
+
+	_, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		DisableGlobal:       true,
+	})
+
+	if !errors.Is(err, ErrExporterTimeout) {
+		t.Fatalf("expected ErrExporterTimeout, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline error to be kept, got %v", err)
+	}
+
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) || providerErr.Stage != StageExporter {
+		t.Errorf("expected an exporter stage error, got %v", err)
+	}
+}
+
+// TestResourceDetectors tests that detected attributes are only added when enabled
+func TestResourceDetectors(t *testing.T) {
+	regionDetector := resource.StringDetector("", "cloud.region", func() (string, error) {
+		return "eu-west-1", nil
+	})
+	versionDetector := resource.StringDetector("", "service.version", func() (string, error) {
+		return "detected", nil
+	})
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected map[attribute.Key]string
+		absent   []attribute.Key
+	}{
+		{
+			name:   "minimal resource by default",
+			absent: []attribute.Key{semconv.HostNameKey, semconv.ProcessPIDKey, "cloud.region"},
+		},
+		{
+			name:   "host detection",
+			config: Config{DetectHost: true},
+			absent: []attribute.Key{semconv.ProcessPIDKey},
+		},
+		{
+			name:   "process detection",
+			config: Config{DetectProcess: true},
+			absent: []attribute.Key{semconv.HostNameKey},
+		},
+		{
+			name:     "custom detector",
+			config:   Config{Detectors: []resource.Detector{regionDetector}},
+			expected: map[attribute.Key]string{"cloud.region": "eu-west-1"},
+		},
+		{
+			name: "configured attributes override detected ones",
+			config: Config{
+				Detectors:          []resource.Detector{versionDetector},
+				ResourceAttributes: map[string]string{"service.version": "configured"},
+			},
+			expected: map[attribute.Key]string{"service.version": "configured"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config
+			cfg.ServiceName = "test-service"
+			cfg.ExporterGRPCAddress = "localhost:4317"
+			cfg.StrictResource = true
+			provider, exporter := newTestProvider(t, &cfg)
+
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			set := spans[0].Resource.Set()
+
+			for key, expected := range tt.expected {
+				if value, _ := set.Value(key); value.AsString() != expected {
+					t.Errorf("expected %s=%q, got %q", key, expected, value.AsString())
+				}
+			}
+			if cfg.DetectHost && !set.HasValue(semconv.HostNameKey) {
+				t.Errorf("expected a detected %s, got %v", semconv.HostNameKey, set)
+			}
+			if cfg.DetectProcess && !set.HasValue(semconv.ProcessPIDKey) {
+				t.Errorf("expected a detected %s, got %v", semconv.ProcessPIDKey, set)
+			}
+			for _, key := range tt.absent {
+				if set.HasValue(key) {
+					t.Errorf("expected no %s attribute, got %v", key, set)
+				}
+			}
+		})
+	}
+}
+
+// TestBatchTuning tests that batch processor settings are applied
+func TestBatchTuning(t *testing.T) {
+	exporter := &stubExporter{}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		BatchTimeout:        10 * time.Millisecond,
+		MaxExportBatchSize:  2,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+
+	for range 5 {
+		_, span := provider.Tracer().Start(context.Background(), "operation")
+		span.End()
+	}
+
+	// The short batch timeout exports without an explicit flush
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		exporter.mu.Lock()
+		exported, calls := exporter.exported, exporter.calls
+		exporter.mu.Unlock()
+
+		if exported == 5 {
+			if calls < 3 {
+				t.Errorf("expected at least 3 exports of at most 2 spans, got %d", calls)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 5 spans to be exported by the batch timeout, got %d", exported)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestSpanLimits tests that attributes, events and links beyond the limits are dropped
+func TestSpanLimits(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:          "test-service",
+		ExporterGRPCAddress:  "localhost:4317",
+		MaxAttributesPerSpan: 2,
+		MaxEventsPerSpan:     1,
+		MaxLinksPerSpan:      1,
+	})
+
+	_, linked := provider.Tracer().Start(context.Background(), "linked")
+	linked.End()
+	link := trace.Link{SpanContext: linked.SpanContext()}
+
+	_, span := provider.Tracer().Start(context.Background(), "limited", trace.WithLinks(link, link))
+	span.SetAttributes(attribute.Int("a", 1), attribute.Int("b", 2), attribute.Int("c", 3))
+	span.AddEvent("first")
+	span.AddEvent("second")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	limited := spans[1]
+	if len(limited.Attributes) != 2 || limited.DroppedAttributes != 1 {
+		t.Errorf("expected 2 attributes and 1 dropped, got %d and %d", len(limited.Attributes), limited.DroppedAttributes)
+	}
+	if len(limited.Events) != 1 || limited.DroppedEvents != 1 {
+		t.Errorf("expected 1 event and 1 dropped, got %d and %d", len(limited.Events), limited.DroppedEvents)
+	}
+	if len(limited.Links) != 1 || limited.DroppedLinks != 1 {
+		t.Errorf("expected 1 link and 1 dropped, got %d and %d", len(limited.Links), limited.DroppedLinks)
+	}
+}
+
+// TestUseSimpleProcessor tests that spans are exported as soon as they end
+func TestUseSimpleProcessor(t *testing.T) {
+	exporter := &stubExporter{}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		UseSimpleProcessor:  true,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	if exporter.exported != 1 {
+		t.Errorf("expected the span to be exported on End, got %d exported", exporter.exported)
+	}
+}
+
+// TestDisableGlobal tests that the global provider is only replaced when allowed
+func TestDisableGlobal(t *testing.T) {
+	tests := []struct {
+		name           string
+		disableGlobal  bool
+		expectReplaced bool
+	}{
+		{name: "global by default", disableGlobal: false, expectReplaced: true},
+		{name: "global disabled", disableGlobal: true, expectReplaced: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := noop.NewTracerProvider()
+			otel.SetTracerProvider(previous)
+
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				DisableGlobal:       tt.disableGlobal,
+			})
+
+			replaced := otel.GetTracerProvider() == trace.TracerProvider(provider.provider)
+			if replaced != tt.expectReplaced {
+				t.Errorf("expected global provider replaced=%v, got %v", tt.expectReplaced, replaced)
+			}
+
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+
+			if spans := flushSpans(t, provider, exporter); len(spans) != 1 {
+				t.Errorf("expected the provider tracer to record spans, got %d", len(spans))
+			}
+		})
+	}
+}
+
+// TestErrorHandlerLogger tests that OpenTelemetry errors such as failed exports reach the Logger
+func TestErrorHandlerLogger(t *testing.T) {
+	previous := otel.GetErrorHandler()
+	t.Cleanup(func() { otel.SetErrorHandler(previous) })
+
+	logger := &captureLogger{}
+	exporter := &stubExporter{errs: []error{errors.New("collector unavailable")}}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		Logger:              logger,
+		BatchTimeout:        10 * time.Millisecond,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logger.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.messages) == 0 || !strings.Contains(logger.messages[0], "collector unavailable") {
+		t.Errorf("expected the export failure to be logged, got %v", logger.messages)
+	}
+}
+
+// TestDisabledTracerProvider tests that a disabled provider is usable but records nothing
+func TestDisabledTracerProvider(t *testing.T) {
+	previous := noop.NewTracerProvider()
+	otel.SetTracerProvider(previous)
+
+	// Only the flag is needed, the collector settings are not validated
+	provider, err := NewTracerProvider(&Config{Disabled: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if otel.GetTracerProvider() != trace.TracerProvider(previous) {
+		t.Error("expected the global provider to be left untouched")
+	}
+
+	for _, tracer := range []trace.Tracer{provider.Tracer(), provider.NamedTracer("library")} {
+		if _, ok := tracer.(noop.Tracer); !ok {
+			t.Errorf("expected a noop tracer, got %T", tracer)
+		}
+
+		_, span := tracer.Start(context.Background(), "operation")
+		if span.IsRecording() {
+			t.Error("expected spans of a disabled provider not to record")
+		}
+		span.End()
+	}
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Errorf("expected no flush error, got %v", err)
+	}
+	if state := provider.ConnState(); state != NoConnState {
+		t.Errorf("expected no connection, got %s", state)
+	}
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected no shutdown error, got %v", err)
+	}
+}
+
+// TestNoopTracerProvider tests that every method of the noop provider is safe to call
+func TestNoopTracerProvider(t *testing.T) {
+	provider := NewNoopTracerProvider()
+
+	if !provider.IsNoop() || !IsNoopTracer(provider.Tracer()) {
+		t.Error("expected a noop provider and tracer")
+	}
+
+	ctx, span := provider.Tracer().Start(context.Background(), "operation")
+	if span.IsRecording() {
+		t.Error("expected spans not to record")
+	}
+	_, child := provider.NamedTracer("library").Start(ctx, "child")
+	child.End()
+	span.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Errorf("expected no flush error, got %v", err)
+	}
+	if err := provider.HealthCheck(context.Background()); err != nil {
+		t.Errorf("expected no health check error, got %v", err)
+	}
+	if stats := provider.Stats(); stats != (Stats{}) {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+	if spans := provider.RecordedSpans(); spans != nil {
+		t.Errorf("expected no recorded spans, got %v", spans)
+	}
+	if err := provider.Reconnect(); !errors.Is(err, ErrReconnectUnsupported) {
+		t.Errorf("expected ErrReconnectUnsupported, got %v", err)
+	}
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected no shutdown error, got %v", err)
+	}
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected repeated shutdowns to return nil, got %v", err)
+	}
+}
+
+// TestIsNoop tests detecting noop providers and tracers
+func TestIsNoop(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+	if provider.IsNoop() {
+		t.Error("expected a real provider not to be noop")
+	}
+	if IsNoopTracer(provider.Tracer()) || IsNoopTracer(provider.NamedTracer("library")) {
+		t.Error("expected the tracers of a real provider not to be noop")
+	}
+
+	disabled, err := NewTracerProvider(&Config{Disabled: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !disabled.IsNoop() {
+		t.Error("expected a disabled provider to be noop")
+	}
+	if !IsNoopTracer(disabled.Tracer()) {
+		t.Error("expected the tracer of a disabled provider to be noop")
+	}
+
+	if !IsNoopTracer(NewTracer(nil)) {
+		t.Error("expected the tracer of a nil config to be noop")
+	}
+	if !IsNoopTracer(nil) {
+		t.Error("expected a nil tracer to be noop")
+	}
+}
+
+// TestRetryConfig tests the export retry policy passed to the exporter
+func TestRetryConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected otlptracegrpc.RetryConfig
+	}{
+		{
+			name:   "OTLP defaults",
+			config: &Config{},
+			expected: otlptracegrpc.RetryConfig{
+				Enabled:         true,
+				InitialInterval: 5 * time.Second,
+				MaxInterval:     30 * time.Second,
+				MaxElapsedTime:  time.Minute,
+			},
+		},
+		{
+			name: "custom intervals",
+			config: &Config{
+				RetryInitialInterval: time.Second,
+				RetryMaxInterval:     10 * time.Second,
+				RetryMaxElapsedTime:  2 * time.Minute,
+			},
+			expected: otlptracegrpc.RetryConfig{
+				Enabled:         true,
+				InitialInterval: time.Second,
+				MaxInterval:     10 * time.Second,
+				MaxElapsedTime:  2 * time.Minute,
+			},
+		},
+		{
+			name:   "disabled",
+			config: &Config{DisableRetry: true},
+			expected: otlptracegrpc.RetryConfig{
+				Enabled:         false,
+				InitialInterval: 5 * time.Second,
+				MaxInterval:     30 * time.Second,
+				MaxElapsedTime:  time.Minute,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := resolveConfig(tt.config)
+			if got := retryConfig(&resolved); got != tt.expected {
+				t.Errorf("expected retry config %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestKeepaliveParams tests the keepalive parameters of the collector connection
+func TestKeepaliveParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected keepalive.ClientParameters
+	}{
+		{
+			name:   "defaults",
+			config: &Config{},
+			expected: keepalive.ClientParameters{
+				Time:    5 * time.Minute,
+				Timeout: 20 * time.Second,
+			},
+		},
+		{
+			name: "custom values",
+			config: &Config{
+				KeepAliveTime:       30 * time.Second,
+				KeepAliveTimeout:    5 * time.Second,
+				PermitWithoutStream: true,
+			},
+			expected: keepalive.ClientParameters{
+				Time:                30 * time.Second,
+				Timeout:             5 * time.Second,
+				PermitWithoutStream: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := resolveConfig(tt.config)
+			if got := keepaliveParams(&resolved); got != tt.expected {
+				t.Errorf("expected keepalive params %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestGRPCExporterOptions tests that compression adds an exporter option
+func TestGRPCExporterOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		compression     string
+		expectedOptions int
+	}{
+		{name: "default", compression: "", expectedOptions: 2},
+		{name: "none", compression: CompressionNone, expectedOptions: 2},
+		{name: "gzip", compression: CompressionGzip, expectedOptions: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := grpcExporterOptions(&Config{Compression: tt.compression}, nil)
+			if len(options) != tt.expectedOptions {
+				t.Errorf("expected %d exporter options, got %d", tt.expectedOptions, len(options))
+			}
+		})
+	}
+}
+
+// startCollectorLater serves a testCollector on a free local address after the delay
+func startCollectorLater(t *testing.T, delay time.Duration) string {
+	t.Helper()
+
+	address := closedAddress(t)
+	timer := time.AfterFunc(delay, func() {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			t.Errorf("failed to listen: %v", err)
+			return
+		}
+		serveTestCollector(t, listener, address)
+	})
+	t.Cleanup(func() { timer.Stop() })
+
+	return address
+}
+
+// TestNewTracerProviderWithRetry tests retrying provider creation
+func TestNewTracerProviderWithRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		address       func(t *testing.T) string
+		attempts      int
+		cancelled     bool
+		expectError   bool
+		errorType     error
+		expectMinTime time.Duration
+	}{
+		{
+			name:     "succeeds on first attempt",
+			address:  func(t *testing.T) string { return newTestCollector(t).address },
+			attempts: 3,
+		},
+		{
+			name:     "succeeds once the collector starts",
+			address:  func(t *testing.T) string { return startCollectorLater(t, 100*time.Millisecond) },
+			attempts: 100,
+		},
+		{
+			name:        "invalid config is not retried",
+			address:     func(t *testing.T) string { return "" },
+			attempts:    3,
+			expectError: true,
+			errorType:   ErrEmptyExporterAddress,
+		},
+		{
+			name:          "returns last error after exhausting attempts",
+			address:       closedAddress,
+			attempts:      3,
+			expectError:   true,
+			errorType:     ErrCollectorUnreachable,
+			expectMinTime: 2 * 10 * time.Millisecond,
+		},
+		{
+			name:        "stops when context is cancelled",
+			address:     closedAddress,
+			attempts:    3,
+			cancelled:   true,
+			expectError: true,
+			errorType:   context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := noop.NewTracerProvider()
+			otel.SetTracerProvider(previous)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			start := time.Now()
+			provider, err := NewTracerProviderWithRetry(ctx, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: tt.address(t),
+				ConnectTimeout:      time.Second,
+			}, tt.attempts, 10*time.Millisecond)
+			elapsed := time.Since(start)
+
+			if !tt.expectError {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				defer provider.Shutdown(context.Background())
+
+				if state := provider.ConnState(); state != connectivity.Ready {
+					t.Errorf("expected a ready connection, got %s", state)
+				}
+				return
+			}
+
+			if err == nil || provider != nil {
+				t.Fatalf("expected error and nil provider, got %v, %v", provider, err)
+			}
+			if tt.errorType != nil && !errors.Is(err, tt.errorType) {
+				t.Errorf("expected error type %v, got %v", tt.errorType, err)
+			}
+			if elapsed < tt.expectMinTime {
+				t.Errorf("expected backoff of at least %v, took %v", tt.expectMinTime, elapsed)
+			}
+			if otel.GetTracerProvider() != trace.TracerProvider(previous) {
+				t.Error("expected failed attempts to leave the global provider untouched")
+			}
+		})
+	}
+}
+
+// TestEmitShutdownSpan tests that a lifecycle span is flushed on shutdown when enabled
+func TestEmitShutdownSpan(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		expectedSpans []string
+	}{
+		{name: "disabled by default", enabled: false, expectedSpans: []string{"work"}},
+		{name: "enabled", enabled: true, expectedSpans: []string{"work", ShutdownSpanName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &stubExporter{}
+			provider, err := newTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				EmitShutdownSpan:    tt.enabled,
+			}, exporter)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			_, span := provider.Tracer().Start(context.Background(), "work")
+			span.End()
+
+			if err := provider.Shutdown(context.Background()); err != nil {
+				t.Fatalf("expected no shutdown error, got %v", err)
+			}
+
+			if !slices.Equal(exporter.names, tt.expectedSpans) {
+				t.Errorf("expected exported spans %v, got %v", tt.expectedSpans, exporter.names)
 			}
 		})
 	}
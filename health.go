@@ -0,0 +1,63 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// ErrCollectorUnreachable is returned by HealthCheck when the collector connection cannot become ready
+var ErrCollectorUnreachable = errors.New("collector is unreachable")
+
+// NoConnState is returned by ConnState for providers without a GRPC connection
+const NoConnState = connectivity.State(-1)
+
+// ConnState returns the current state of the GRPC connection to the ExporterGRPCAddress collector without
+// triggering a connection attempt, e.g. for dashboards. It returns NoConnState for
+// providers without a GRPC connection, such as those using WriterExporter.
+func (tp *TracerProvider) ConnState() connectivity.State {
+	grpcConn := tp.connection()
+	if grpcConn == nil {
+		return NoConnState
+	}
+
+	return grpcConn.GetState()
+}
+
+// HealthCheck connects to the collectors, including those of ExporterAddresses, and waits
+// until every connection is ready, e.g. for a readiness probe. GRPC connections are established
+// lazily, so NewTracerProvider succeeds even when a collector is down. It fails as soon as a
+// connection attempt fails, or when ctx is done. Providers without a GRPC connection, such as
+// those using WriterExporter, are always healthy.
+func (tp *TracerProvider) HealthCheck(ctx context.Context) error {
+	for _, grpcConn := range tp.connections() {
+		if err := waitForReady(ctx, grpcConn); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrCollectorUnreachable, grpcConn.Target(), err)
+		}
+	}
+
+	return nil
+}
+
+// waitForReady connects and waits until the connection is ready, failing as soon as a
+// connection attempt fails or ctx is done
+func waitForReady(ctx context.Context, grpcConn *grpc.ClientConn) error {
+	grpcConn.Connect()
+
+	for {
+		state := grpcConn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("connection is %s", state)
+		}
+
+		if !grpcConn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+}
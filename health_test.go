@@ -0,0 +1,179 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/connectivity"
+)
+
+// closedAddress returns a local address with no listener, refusing connections
+func closedAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	return address
+}
+
+// TestHealthCheck tests collector reachability against accepting and refusing listeners
+func TestHealthCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		address     func(t *testing.T) string
+		expectedErr error
+	}{
+		{
+			name:        "collector accepts connections",
+			address:     func(t *testing.T) string { return newTestCollector(t).address },
+			expectedErr: nil,
+		},
+		{
+			name:        "collector refuses connections",
+			address:     closedAddress,
+			expectedErr: ErrCollectorUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewTracerProvider(&Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: tt.address(t),
+				ShutdownTimeout:     time.Second,
+			})
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+			defer provider.Shutdown(context.Background())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := provider.HealthCheck(ctx); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+// TestHealthCheckWithoutConnection tests that providers without a GRPC connection are healthy
+func TestHealthCheckWithoutConnection(t *testing.T) {
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:    "test-service",
+		WriterExporter: io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	if err := provider.HealthCheck(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestConnState tests the reported connection state transitions with a live collector
+func TestConnState(t *testing.T) {
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: newTestCollector(t).address,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if state := provider.ConnState(); state != connectivity.Idle {
+		t.Errorf("expected idle connection before use, got %s", state)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := provider.HealthCheck(ctx); err != nil {
+		t.Fatalf("expected the collector to be reachable, got %v", err)
+	}
+
+	if state := provider.ConnState(); state != connectivity.Ready {
+		t.Errorf("expected ready connection, got %s", state)
+	}
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+
+	if state := provider.ConnState(); state != connectivity.Shutdown {
+		t.Errorf("expected shut down connection, got %s", state)
+	}
+}
+
+// TestConnStateWithoutConnection tests the sentinel state of providers without a GRPC connection
+func TestConnStateWithoutConnection(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	if state := provider.ConnState(); state != NoConnState {
+		t.Errorf("expected %s, got %s", NoConnState, state)
+	}
+}
+
+// TestBlockOnConnect tests that provider creation waits for the collector when requested
+func TestBlockOnConnect(t *testing.T) {
+	tests := []struct {
+		name        string
+		address     func(t *testing.T) string
+		expectedErr error
+	}{
+		{
+			name:        "reachable collector",
+			address:     func(t *testing.T) string { return newTestCollector(t).address },
+			expectedErr: nil,
+		},
+		{
+			name:        "unreachable collector",
+			address:     closedAddress,
+			expectedErr: ErrCollectorUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := noop.NewTracerProvider()
+			otel.SetTracerProvider(previous)
+
+			provider, err := NewTracerProvider(&Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: tt.address(t),
+				BlockOnConnect:      true,
+				ConnectTimeout:      5 * time.Second,
+			})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				if provider != nil {
+					t.Error("expected no provider on connection failure")
+				}
+				if otel.GetTracerProvider() != trace.TracerProvider(previous) {
+					t.Error("expected the global provider to be left untouched on connection failure")
+				}
+				return
+			}
+			defer provider.Shutdown(context.Background())
+
+			if state := provider.ConnState(); state != connectivity.Ready {
+				t.Errorf("expected a ready connection, got %s", state)
+			}
+		})
+	}
+}
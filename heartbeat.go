@@ -0,0 +1,42 @@
+package goteletracer
+
+import (
+	"context"
+	"time"
+)
+
+// HeartbeatSpanName is the name of the periodic span emitted when HeartbeatInterval is set
+const HeartbeatSpanName = "goteletracer.heartbeat"
+
+// startHeartbeat emits a heartbeat span every interval until stopHeartbeat is called
+func (tp *TracerProvider) startHeartbeat(interval time.Duration) {
+	tp.heartbeatStop = make(chan struct{})
+	tp.heartbeatDone = make(chan struct{})
+
+	go func() {
+		defer close(tp.heartbeatDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-tp.heartbeatStop:
+				return
+			case <-ticker.C:
+				_, span := tp.tracer.Start(context.Background(), HeartbeatSpanName)
+				span.End()
+			}
+		}
+	}()
+}
+
+// stopHeartbeat stops the heartbeat goroutine and waits for it to exit
+func (tp *TracerProvider) stopHeartbeat() {
+	if tp.heartbeatStop == nil {
+		return
+	}
+
+	close(tp.heartbeatStop)
+	<-tp.heartbeatDone
+}
@@ -0,0 +1,50 @@
+package goteletracer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestHeartbeat tests that heartbeat spans are emitted and the goroutine stops on shutdown
+func TestHeartbeat(t *testing.T) {
+	exporter := &stubExporter{}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		HeartbeatInterval:   5 * time.Millisecond,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+
+	select {
+	case <-provider.heartbeatDone:
+	default:
+		t.Fatal("expected the heartbeat goroutine to stop on shutdown")
+	}
+
+	if len(exporter.names) == 0 {
+		t.Fatal("expected heartbeat spans to be exported")
+	}
+	for _, name := range exporter.names {
+		if name != HeartbeatSpanName {
+			t.Errorf("expected only heartbeat spans, got %q", name)
+		}
+	}
+}
+
+// TestHeartbeatDisabled tests that no heartbeat goroutine runs by default
+func TestHeartbeatDisabled(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	if provider.heartbeatStop != nil {
+		t.Error("expected no heartbeat without an interval")
+	}
+}
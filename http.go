@@ -0,0 +1,160 @@
+package goteletracer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultTraceIDHeader is the response header used by WithContextInjectionMiddleware
+// when no header name is provided
+const DefaultTraceIDHeader = "X-Trace-Id"
+
+// WithContextInjectionMiddleware returns HTTP middleware writing the trace ID of the
+// span active in the request context into the given response header before calling
+// the next handler, so support can look up the trace behind a user's request.
+// An empty header defaults to X-Trace-Id. It must run inside the middleware that
+// starts the server span; requests without a valid span get no header.
+func WithContextInjectionMiddleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultTraceIDHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.HasTraceID() {
+				w.Header().Set(header, spanContext.TraceID().String())
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HTTPMiddleware returns HTTP server middleware starting a server span per request with
+// the provider's tracer, continuing the trace propagated in the request headers with the
+// provider's propagators. The span is named after the method and, when the next handler
+// is an http.ServeMux, the matched route, e.g. "GET /users/{id}". It records the method,
+// path, route and response status code with the semconv v1.27 keys, i.e. http.request.method
+// and http.response.status_code rather than the older http.method and http.status_code;
+// 5xx responses set an error status. The ResponseWriter handed to next still implements
+// http.Flusher, and http.Hijacker when the server's does, for streaming and websockets.
+func (tp *TracerProvider) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tp.textMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tp.tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPathKey.String(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		// ServeMux sets the matched pattern on the request it is given
+		r = r.WithContext(ctx)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder.responseWriter(), r)
+
+		if r.Pattern != "" {
+			route := r.Pattern
+			if _, path, ok := strings.Cut(route, " "); ok {
+				route = path
+			}
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRouteKey.String(route))
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCodeKey.Int(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", recorder.status))
+		}
+	})
+}
+
+// textMapPropagator returns the provider's propagator, or the global one for disabled providers
+func (tp *TracerProvider) textMapPropagator() propagation.TextMapPropagator {
+	if tp.propagator == nil {
+		return otel.GetTextMapPropagator()
+	}
+
+	return tp.propagator
+}
+
+// statusRecorder is a ResponseWriter remembering the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the first status code and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the body, implying a 200 status when no status was written
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(body)
+}
+
+// Flush flushes the wrapped ResponseWriter when it supports flushing, e.g. for
+// server-sent events, implying a 200 status when no status was written
+func (r *statusRecorder) Flush() {
+	r.wroteHeader = true
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ReadFrom copies the body from src, using the wrapped ResponseWriter's io.ReaderFrom
+// when it has one, implying a 200 status when no status was written
+func (r *statusRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.wroteHeader = true
+	if readerFrom, ok := r.ResponseWriter.(io.ReaderFrom); ok {
+		return readerFrom.ReadFrom(src)
+	}
+
+	return io.Copy(r.ResponseWriter, src)
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// responseWriter returns the recorder to hand to the handler, implementing http.Hijacker
+// only when the wrapped ResponseWriter does so handlers can still detect its support
+func (r *statusRecorder) responseWriter() http.ResponseWriter {
+	if _, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijackStatusRecorder{r}
+	}
+
+	return r
+}
+
+// hijackStatusRecorder is a statusRecorder whose wrapped ResponseWriter supports hijacking,
+// e.g. for websocket upgrades
+type hijackStatusRecorder struct {
+	*statusRecorder
+}
+
+// Hijack takes over the connection of the wrapped ResponseWriter
+func (r hijackStatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.ResponseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,238 @@
+package goteletracer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestWithContextInjectionMiddleware tests that the active trace ID is written to the response
+func TestWithContextInjectionMiddleware(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	ctx, span := provider.Tracer().Start(context.Background(), "request")
+	defer span.End()
+	traceID := span.SpanContext().TraceID().String()
+
+	tests := []struct {
+		name           string
+		header         string
+		ctx            context.Context
+		expectedHeader string
+		expectedValue  string
+	}{
+		{name: "default header", header: "", ctx: ctx, expectedHeader: DefaultTraceIDHeader, expectedValue: traceID},
+		{name: "custom header", header: "X-Request-Trace", ctx: ctx, expectedHeader: "X-Request-Trace", expectedValue: traceID},
+		{name: "no active span", header: "", ctx: context.Background(), expectedHeader: DefaultTraceIDHeader, expectedValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := WithContextInjectionMiddleware(tt.header)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(tt.ctx)
+			handler.ServeHTTP(recorder, request)
+
+			if !called {
+				t.Fatal("expected the next handler to be called")
+			}
+			if got := recorder.Header().Get(tt.expectedHeader); got != tt.expectedValue {
+				t.Errorf("expected header %q to be %q, got %q", tt.expectedHeader, tt.expectedValue, got)
+			}
+		})
+	}
+}
+
+// TestHTTPMiddleware tests that server spans are started per request with HTTP attributes
+func TestHTTPMiddleware(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !trace.SpanContextFromContext(r.Context()).IsValid() {
+			t.Error("expected the server span in the handler context")
+		}
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	server := httptest.NewServer(provider.HTTPMiddleware(mux))
+	defer server.Close()
+
+	parentTraceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		name           string
+		path           string
+		traceparent    string
+		expectedName   string
+		expectedRoute  string
+		expectedStatus int
+		expectedCode   codes.Code
+	}{
+		{
+			name:           "routed request continuing a trace",
+			path:           "/users/42",
+			traceparent:    "00-" + parentTraceID + "-00f067aa0ba902b7-01",
+			expectedName:   "GET /users/{id}",
+			expectedRoute:  "/users/{id}",
+			expectedStatus: http.StatusOK,
+			expectedCode:   codes.Unset,
+		},
+		{
+			name:           "server error",
+			path:           "/fail",
+			expectedName:   "GET /fail",
+			expectedRoute:  "/fail",
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   codes.Error,
+		},
+		{
+			name:           "unmatched route",
+			path:           "/missing",
+			expectedName:   "GET",
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			request, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			if tt.traceparent != "" {
+				request.Header.Set("traceparent", tt.traceparent)
+			}
+
+			response, err := server.Client().Do(request)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			response.Body.Close()
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			span := spans[0]
+
+			if span.Name != tt.expectedName {
+				t.Errorf("expected span name %q, got %q", tt.expectedName, span.Name)
+			}
+			if span.SpanKind != trace.SpanKindServer {
+				t.Errorf("expected a server span, got %v", span.SpanKind)
+			}
+			if tt.traceparent != "" && span.SpanContext.TraceID().String() != parentTraceID {
+				t.Errorf("expected the propagated trace ID, got %s", span.SpanContext.TraceID())
+			}
+			if value, _ := spanAttribute(span, semconv.HTTPRequestMethodKey); value.AsString() != http.MethodGet {
+				t.Errorf("expected method attribute, got %v", span.Attributes)
+			}
+			if value, _ := spanAttribute(span, semconv.HTTPResponseStatusCodeKey); value.AsInt64() != int64(tt.expectedStatus) {
+				t.Errorf("expected status code %d, got %v", tt.expectedStatus, value.AsInt64())
+			}
+			if value, _ := spanAttribute(span, semconv.HTTPRouteKey); value.AsString() != tt.expectedRoute {
+				t.Errorf("expected route %q, got %q", tt.expectedRoute, value.AsString())
+			}
+			if span.Status.Code != tt.expectedCode {
+				t.Errorf("expected status %v, got %v", tt.expectedCode, span.Status.Code)
+			}
+		})
+	}
+}
+
+// TestHTTPMiddlewareFlushAndHijack tests that handlers can flush streamed responses and
+// hijack connections through the middleware
+func TestHTTPMiddlewareFlushAndHijack(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("expected the response writer to support flushing")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: first\n\n"))
+		flusher.Flush()
+	})
+	mux.HandleFunc("/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("expected the response writer to support hijacking")
+			return
+		}
+		conn, buffer, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack the connection: %v", err)
+			return
+		}
+		defer conn.Close()
+		buffer.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		buffer.Flush()
+	})
+	handler := provider.HTTPMiddleware(mux)
+
+	t.Run("flush", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+		if !recorder.Flushed {
+			t.Error("expected the response to be flushed")
+		}
+		if body := recorder.Body.String(); body != "data: first\n\n" {
+			t.Errorf("unexpected body %q", body)
+		}
+	})
+
+	t.Run("hijack", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		request, err := http.NewRequest(http.MethodGet, server.URL+"/upgrade", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		request.Header.Set("Connection", "Upgrade")
+		request.Header.Set("Upgrade", "test")
+
+		response, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusSwitchingProtocols {
+			t.Errorf("expected status %d, got %d", http.StatusSwitchingProtocols, response.StatusCode)
+		}
+	})
+
+	if spans := flushSpans(t, provider, exporter); len(spans) != 2 {
+		t.Errorf("expected 2 spans, got %d", len(spans))
+	}
+}
+
+// TestStatusRecorderHijackSupport tests that the recorder only claims hijacking support
+// when the wrapped ResponseWriter has it
+func TestStatusRecorderHijackSupport(t *testing.T) {
+	recorder := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	if _, ok := recorder.responseWriter().(http.Hijacker); ok {
+		t.Error("expected no hijacking support from a writer without it")
+	}
+}
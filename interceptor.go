@@ -0,0 +1,96 @@
+package goteletracer
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a GRPC server interceptor starting a server span per call
+// with the provider's tracer, continuing the trace propagated in the incoming metadata with
+// the provider's propagators. Spans are named after the full method, e.g.
+// "helloworld.Greeter/SayHello", and record the GRPC status code.
+func (tp *TracerProvider) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = tp.textMapPropagator().Extract(ctx, MetadataCarrier(md))
+
+		ctx, span := tp.tracer.Start(ctx, rpcSpanName(info.FullMethod),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(rpcAttributes(info.FullMethod)...),
+		)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		endRPCSpan(span, err)
+
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor returns a GRPC client interceptor starting a client span per call
+// with the provider's tracer and injecting its context into the outgoing metadata with
+// the provider's propagators. Spans are named after the full method and record the GRPC
+// status code.
+func (tp *TracerProvider) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := tp.tracer.Start(ctx, rpcSpanName(method),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(rpcAttributes(method)...),
+		)
+		defer span.End()
+
+		// Copy the metadata so the caller's outgoing metadata is not modified
+		md, ok := metadata.FromOutgoingContext(ctx)
+		if ok {
+			md = md.Copy()
+		} else {
+			md = metadata.MD{}
+		}
+		tp.textMapPropagator().Inject(ctx, MetadataCarrier(md))
+
+		err := invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+		endRPCSpan(span, err)
+
+		return err
+	}
+}
+
+// rpcSpanName returns the span name of a full GRPC method, e.g. "/pkg.Service/Method"
+// becomes "pkg.Service/Method"
+func rpcSpanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// rpcAttributes returns the RPC attributes of a full GRPC method
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	attributes := []attribute.KeyValue{semconv.RPCSystemGRPC}
+
+	service, method, ok := strings.Cut(rpcSpanName(fullMethod), "/")
+	if !ok {
+		return attributes
+	}
+
+	return append(attributes,
+		semconv.RPCServiceKey.String(service),
+		semconv.RPCMethodKey.String(method),
+	)
+}
+
+// endRPCSpan records the GRPC status code of a call and an error status when it failed
+func endRPCSpan(span trace.Span, err error) {
+	rpcStatus := status.Convert(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(rpcStatus.Code())))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, rpcStatus.Message())
+	}
+}
@@ -0,0 +1,120 @@
+package goteletracer
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+	collector_trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	grpc_codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newInterceptedConn serves a testCollector over an in-memory listener with the provider's
+// server interceptor and returns a client connection using its client interceptor
+func newInterceptedConn(t *testing.T, provider *TracerProvider) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(provider.UnaryServerInterceptor()))
+	collector_trace.RegisterTraceServiceServer(server, &testCollector{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(provider.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// spanByKind returns the first span stub of the given kind
+func spanByKind(t *testing.T, spans tracetest.SpanStubs, kind trace.SpanKind) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.SpanKind == kind {
+			return span
+		}
+	}
+
+	t.Fatalf("expected a %s span, got %v", kind, spans)
+	return tracetest.SpanStub{}
+}
+
+// TestUnaryInterceptors tests that client and server spans are produced per call in one trace
+func TestUnaryInterceptors(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+	client := collector_trace.NewTraceServiceClient(newInterceptedConn(t, provider))
+
+	if _, err := client.Export(context.Background(), &collector_trace.ExportTraceServiceRequest{}); err != nil {
+		t.Fatalf("expected no call error, got %v", err)
+	}
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected a client and a server span, got %d", len(spans))
+	}
+
+	clientSpan := spanByKind(t, spans, trace.SpanKindClient)
+	serverSpan := spanByKind(t, spans, trace.SpanKindServer)
+
+	const expectedName = "opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	for _, span := range []tracetest.SpanStub{clientSpan, serverSpan} {
+		if span.Name != expectedName {
+			t.Errorf("expected span name %q, got %q", expectedName, span.Name)
+		}
+		if value, _ := spanAttribute(span, semconv.RPCGRPCStatusCodeKey); value.AsInt64() != int64(grpc_codes.OK) {
+			t.Errorf("%s: expected OK status code, got %v", span.SpanKind, value.AsInt64())
+		}
+		if value, _ := spanAttribute(span, semconv.RPCMethodKey); value.AsString() != "Export" {
+			t.Errorf("%s: expected rpc.method attribute, got %v", span.SpanKind, span.Attributes)
+		}
+	}
+
+	if serverSpan.Parent.SpanID() != clientSpan.SpanContext.SpanID() {
+		t.Errorf("expected the server span to continue the client span, got parent %s", serverSpan.Parent.SpanID())
+	}
+}
+
+// TestUnaryClientInterceptorError tests that failed calls record their status code and an error
+func TestUnaryClientInterceptorError(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+	conn := newInterceptedConn(t, provider)
+
+	err := conn.Invoke(context.Background(), "/test.Missing/Call", &collector_trace.ExportTraceServiceRequest{}, &collector_trace.ExportTraceServiceResponse{})
+	if status.Code(err) != grpc_codes.Unimplemented {
+		t.Fatalf("expected an unimplemented error, got %v", err)
+	}
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected only the client span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "test.Missing/Call" {
+		t.Errorf("expected span name %q, got %q", "test.Missing/Call", span.Name)
+	}
+	if value, _ := spanAttribute(span, semconv.RPCGRPCStatusCodeKey); value.AsInt64() != int64(grpc_codes.Unimplemented) {
+		t.Errorf("expected unimplemented status code, got %v", value.AsInt64())
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("expected error status, got %+v", span.Status)
+	}
+}
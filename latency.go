@@ -0,0 +1,137 @@
+package goteletracer
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// latencyBucketBounds are the upper bounds of the span duration histogram buckets
+var latencyBucketBounds = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	time.Duration(math.MaxInt64),
+}
+
+// DurationStats summarizes the durations of the spans ended with the same name
+type DurationStats struct {
+	// Count is the number of spans
+	Count int64
+	// Total is the sum of the span durations
+	Total time.Duration
+	// Min and Max are the shortest and longest span durations
+	Min time.Duration
+	Max time.Duration
+	// Buckets is the duration histogram, counting each span in the first bucket whose
+	// upper bound is not below its duration. The last bucket is unbounded.
+	Buckets []LatencyBucket
+}
+
+// LatencyBucket is a histogram bucket of DurationStats
+type LatencyBucket struct {
+	// UpperBound is the inclusive upper bound of the bucket
+	UpperBound time.Duration
+	// Count is the number of spans in the bucket
+	Count int64
+}
+
+// Mean returns the average span duration, or zero when no span was recorded
+func (s DurationStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Total / time.Duration(s.Count)
+}
+
+// latencyStatsProcessor is a span processor recording the duration of ended spans per span name
+type latencyStatsProcessor struct {
+	mu    sync.Mutex
+	stats map[string]*DurationStats
+}
+
+// newLatencyStatsProcessor creates an empty latencyStatsProcessor
+func newLatencyStatsProcessor() *latencyStatsProcessor {
+	return &latencyStatsProcessor{
+		stats: make(map[string]*DurationStats),
+	}
+}
+
+// OnStart does nothing
+func (p *latencyStatsProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {}
+
+// OnEnd records the span duration under the span name
+func (p *latencyStatsProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	duration := s.EndTime().Sub(s.StartTime())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats, ok := p.stats[s.Name()]
+	if !ok {
+		stats = &DurationStats{
+			Min:     duration,
+			Max:     duration,
+			Buckets: make([]LatencyBucket, len(latencyBucketBounds)),
+		}
+		for i, bound := range latencyBucketBounds {
+			stats.Buckets[i].UpperBound = bound
+		}
+		p.stats[s.Name()] = stats
+	}
+
+	stats.Count++
+	stats.Total += duration
+	stats.Min = min(stats.Min, duration)
+	stats.Max = max(stats.Max, duration)
+
+	bucket, _ := slices.BinarySearch(latencyBucketBounds, duration)
+	stats.Buckets[bucket].Count++
+}
+
+// Shutdown does nothing
+func (p *latencyStatsProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *latencyStatsProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// snapshot returns a copy of the recorded stats
+func (p *latencyStatsProcessor) snapshot() map[string]DurationStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make(map[string]DurationStats, len(p.stats))
+	for name, stats := range p.stats {
+		copied := *stats
+		copied.Buckets = slices.Clone(stats.Buckets)
+		snapshot[name] = copied
+	}
+
+	return snapshot
+}
+
+// LatencyStats returns the duration stats of the spans ended so far keyed by span name,
+// giving latency metrics without a metrics pipeline. Only spans recorded by the sampler
+// are counted. It returns nil unless CollectLatencyStats is set.
+func (tp *TracerProvider) LatencyStats() map[string]DurationStats {
+	if tp.latency == nil {
+		return nil
+	}
+
+	return tp.latency.snapshot()
+}
@@ -0,0 +1,79 @@
+package goteletracer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestLatencyStats tests the per span name duration stats of spans with known durations
+func TestLatencyStats(t *testing.T) {
+	provider, _ := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		CollectLatencyStats: true,
+	})
+
+	start := time.Now()
+	for _, span := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"query", 2 * time.Millisecond},
+		{"query", 8 * time.Millisecond},
+		{"query", 50 * time.Millisecond},
+		{"request", 2 * time.Minute},
+	} {
+		_, s := provider.Tracer().Start(context.Background(), span.name, trace.WithTimestamp(start))
+		s.End(trace.WithTimestamp(start.Add(span.duration)))
+	}
+
+	stats := provider.LatencyStats()
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for 2 span names, got %v", stats)
+	}
+
+	query := stats["query"]
+	if query.Count != 3 || query.Total != 60*time.Millisecond {
+		t.Errorf("expected 3 query spans totaling 60ms, got %d totaling %v", query.Count, query.Total)
+	}
+	if query.Min != 2*time.Millisecond || query.Max != 50*time.Millisecond || query.Mean() != 20*time.Millisecond {
+		t.Errorf("expected min 2ms, max 50ms and mean 20ms, got %v, %v and %v", query.Min, query.Max, query.Mean())
+	}
+
+	expectedBuckets := map[time.Duration]int64{
+		5 * time.Millisecond:  1,
+		10 * time.Millisecond: 1,
+		50 * time.Millisecond: 1,
+	}
+	for _, bucket := range query.Buckets {
+		if bucket.Count != expectedBuckets[bucket.UpperBound] {
+			t.Errorf("bucket %v: expected %d spans, got %d", bucket.UpperBound, expectedBuckets[bucket.UpperBound], bucket.Count)
+		}
+	}
+
+	request := stats["request"]
+	if last := request.Buckets[len(request.Buckets)-1]; last.Count != 1 {
+		t.Errorf("expected the long span in the unbounded bucket, got %+v", request.Buckets)
+	}
+
+	// Snapshots are not affected by later spans
+	_, s := provider.Tracer().Start(context.Background(), "query")
+	s.End()
+	if stats["query"].Count != 3 || provider.LatencyStats()["query"].Count != 4 {
+		t.Error("expected LatencyStats to return independent snapshots")
+	}
+}
+
+// TestLatencyStatsDisabled tests that no stats are collected by default
+func TestLatencyStatsDisabled(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	if stats := provider.LatencyStats(); stats != nil {
+		t.Errorf("expected no stats, got %v", stats)
+	}
+}
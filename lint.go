@@ -0,0 +1,51 @@
+package goteletracer
+
+import (
+	"fmt"
+	"time"
+)
+
+// minRecommendedShutdownTimeout is the shutdown timeout below which Lint warns
+// that buffered spans may not be flushed in time
+const minRecommendedShutdownTimeout = time.Second
+
+// Lint validates the config without constructing a provider.
+// It returns the config with defaults applied, advisory warnings about risky
+// settings, and an error for hard validation failures.
+func Lint(cfg *Config) (resolved Config, warnings []string, err error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	resolved = resolveConfig(cfg)
+
+	if resolved.WriterExporter == nil && resolved.TLS == nil && !resolved.InsecureSkipVerify {
+		warnings = append(warnings, "exporter connection uses insecure transport without TLS")
+	}
+
+	if resolved.WriterExporter == nil && resolved.InsecureSkipVerify {
+		warnings = append(warnings, "InsecureSkipVerify disables collector certificate verification and must not be used in production")
+	}
+
+	if resolved.ShutdownTimeout < minRecommendedShutdownTimeout {
+		warnings = append(warnings, fmt.Sprintf("shutdown timeout %s is below %s and may not flush buffered spans", resolved.ShutdownTimeout, minRecommendedShutdownTimeout))
+	}
+
+	if cfg.ShutdownTimeout > resolved.MaxShutdownTimeout {
+		warnings = append(warnings, fmt.Sprintf("shutdown timeout %s exceeds the maximum and is capped to %s", cfg.ShutdownTimeout, resolved.MaxShutdownTimeout))
+	}
+
+	if resolved.Sampler == nil && resolved.SamplingRatio == 0 && resolved.RecordAllSampleRatio == 0 && len(resolved.SpanKindSamplingRatios) == 0 {
+		warnings = append(warnings, "every span is sampled (AlwaysSample), which can be expensive for high-throughput services")
+	}
+
+	if resolved.RecordGoroutineID {
+		warnings = append(warnings, "RecordGoroutineID parses the runtime stack on every span start and adds overhead")
+	}
+
+	if resolved.Clock != nil {
+		warnings = append(warnings, "Clock overrides span timestamps and is intended for tests only")
+	}
+
+	return resolved, warnings, nil
+}
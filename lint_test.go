@@ -0,0 +1,135 @@
+package goteletracer
+
+import (
+	"crypto/tls"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestLint tests resolved defaults, warnings and hard errors returned by Lint
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           *Config
+		expectedErr      error
+		expectedWarnings []string
+	}{
+		{
+			name:        "nil config",
+			config:      nil,
+			expectedErr: ErrNilConfig,
+		},
+		{
+			name: "invalid address",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost",
+			},
+			expectedErr: ErrInvalidExporterAddress,
+		},
+		{
+			name: "defaults",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+			},
+			expectedWarnings: []string{"insecure transport", "AlwaysSample"},
+		},
+		{
+			name: "risky settings",
+			config: &Config{
+				ServiceName:            "test-service",
+				ExporterGRPCAddress:    "localhost:4317",
+				ShutdownTimeout:        10 * time.Millisecond,
+				SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindInternal: 0.1},
+				RecordGoroutineID:      true,
+				Clock:                  time.Now,
+			},
+			expectedWarnings: []string{"insecure transport", "shutdown timeout", "RecordGoroutineID", "Clock"},
+		},
+		{
+			name: "TLS",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				TLS:                 &tls.Config{MinVersion: tls.VersionTLS12},
+			},
+			expectedWarnings: []string{"AlwaysSample"},
+		},
+		{
+			name: "InsecureSkipVerify",
+			config: &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				InsecureSkipVerify:  true,
+			},
+			expectedWarnings: []string{"certificate verification", "AlwaysSample"},
+		},
+		{
+			name: "excessive shutdown timeout",
+			config: &Config{
+				ServiceName:            "test-service",
+				ExporterGRPCAddress:    "localhost:4317",
+				ShutdownTimeout:        24 * time.Hour,
+				SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindInternal: 0.1},
+			},
+			expectedWarnings: []string{"insecure transport", "capped"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, warnings, err := Lint(tt.config)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(warnings) != len(tt.expectedWarnings) {
+				t.Fatalf("expected %d warnings, got %d: %v", len(tt.expectedWarnings), len(warnings), warnings)
+			}
+			for i, expected := range tt.expectedWarnings {
+				if !strings.Contains(warnings[i], expected) {
+					t.Errorf("warning %d: expected to mention %q, got %q", i, expected, warnings[i])
+				}
+			}
+
+			if resolved.ShutdownTimeout <= 0 {
+				t.Errorf("expected resolved shutdown timeout, got %v", resolved.ShutdownTimeout)
+			}
+			if resolved.Logger == nil {
+				t.Error("expected resolved logger")
+			}
+		})
+	}
+}
+
+// TestLintDoesNotModifyConfig tests that Lint resolves defaults on a copy
+func TestLintDoesNotModifyConfig(t *testing.T) {
+	cfg := &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+	}
+
+	resolved, _, err := Lint(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resolved.ShutdownTimeout != defaultShutdownTimeout() {
+		t.Errorf("expected default shutdown timeout %v, got %v", defaultShutdownTimeout(), resolved.ShutdownTimeout)
+	}
+	if cfg.ShutdownTimeout != 0 || cfg.Logger != nil {
+		t.Error("expected the original config to be left untouched")
+	}
+}
@@ -0,0 +1,130 @@
+package goteletracer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsHandler returns an HTTP handler serving the provider's Stats in the Prometheus
+// text format, e.g. mounted at /metrics, so operators can scrape export success and
+// failure counts and, with TrackQueueDepth, the export queue depth. The counters are the
+// ones Stats reads; nothing is collected beyond them until the handler is scraped.
+func (tp *TracerProvider) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metricsContentType)
+		writeMetrics(w, tp.Stats())
+	})
+}
+
+// writeMetrics writes the stats as Prometheus metrics
+func writeMetrics(w io.Writer, stats Stats) {
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value int64
+	}{
+		{"goteletracer_spans_exported_total", "counter", "Spans exported successfully.", stats.Exported},
+		{"goteletracer_spans_dropped_total", "counter", "Spans the exporter failed to export.", stats.Dropped},
+		{"goteletracer_spans_fallback_exported_total", "counter", "Spans exported through the fallback exporter.", stats.FallbackExported},
+		{"goteletracer_spans_export_limit_dropped_total", "counter", "Spans dropped because the in-flight export limit was reached.", stats.ExportLimitDropped},
+		{"goteletracer_invalid_link_attributes_total", "counter", "Link attribute pairs skipped for invalid IDs.", stats.InvalidLinkAttributes},
+		{"goteletracer_malformed_contexts_total", "counter", "Incoming trace contexts that could not be parsed.", stats.MalformedContexts},
+		{"goteletracer_span_name_violations_total", "counter", "Spans started with a name rejected by the validator.", stats.SpanNameViolations},
+		{"goteletracer_export_queue_spans", "gauge", "Estimated number of spans waiting in the export queue, zero unless TrackQueueDepth is set.", stats.QueueDepth},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+	}
+}
+
+// queueTracker estimates the number of spans waiting in the export queue from the spans
+// handed to the export processor and the spans it handed to the exporter. It only counts
+// and never drops spans itself, so the batch processor keeps its own drop behavior.
+// Spans the batch processor dropped on a full queue are never exported, so the estimate
+// is clamped to the queue capacity and resynchronized whenever the processor is flushed.
+type queueTracker struct {
+	enqueued atomic.Int64
+	dequeued atomic.Int64
+	capacity int64
+}
+
+// newQueueTracker creates a queueTracker for a queue holding at most capacity spans
+func newQueueTracker(capacity int) *queueTracker {
+	return &queueTracker{capacity: int64(capacity)}
+}
+
+// depth returns the estimated number of spans waiting to be exported
+func (q *queueTracker) depth() int64 {
+	return min(max(q.enqueued.Load()-q.dequeued.Load(), 0), q.capacity)
+}
+
+// drained records that every span enqueued up to enqueued has left the queue, either
+// exported or dropped by the batch processor
+func (q *queueTracker) drained(enqueued int64) {
+	for {
+		dequeued := q.dequeued.Load()
+		if dequeued >= enqueued || q.dequeued.CompareAndSwap(dequeued, enqueued) {
+			return
+		}
+	}
+}
+
+// processor wraps the export processor to count the sampled spans it is given
+func (q *queueTracker) processor(processor sdk_trace.SpanProcessor) sdk_trace.SpanProcessor {
+	return &queueProcessor{SpanProcessor: processor, tracker: q}
+}
+
+// exporter wraps the exporter to count the spans taken out of the queue
+func (q *queueTracker) exporter(exporter sdk_trace.SpanExporter) sdk_trace.SpanExporter {
+	return &queueExporter{SpanExporter: exporter, tracker: q}
+}
+
+// queueProcessor counts the spans entering the export queue
+type queueProcessor struct {
+	sdk_trace.SpanProcessor
+	tracker *queueTracker
+}
+
+// OnEnd counts sampled spans, the only ones the export processor queues, and passes
+// every span on
+func (p *queueProcessor) OnEnd(span sdk_trace.ReadOnlySpan) {
+	if span.SpanContext().IsSampled() {
+		p.tracker.enqueued.Add(1)
+	}
+
+	p.SpanProcessor.OnEnd(span)
+}
+
+// ForceFlush flushes the export processor; once it succeeded, every span counted before
+// the flush has left the queue
+func (p *queueProcessor) ForceFlush(ctx context.Context) error {
+	enqueued := p.tracker.enqueued.Load()
+	if err := p.SpanProcessor.ForceFlush(ctx); err != nil {
+		return err
+	}
+
+	p.tracker.drained(enqueued)
+	return nil
+}
+
+// queueExporter counts the spans leaving the export queue
+type queueExporter struct {
+	sdk_trace.SpanExporter
+	tracker *queueTracker
+}
+
+// ExportSpans counts the spans and exports them
+func (e *queueExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	e.tracker.dequeued.Add(int64(len(spans)))
+	return e.SpanExporter.ExportSpans(ctx, spans)
+}
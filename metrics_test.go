@@ -0,0 +1,171 @@
+package goteletracer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// scrapeMetrics requests the provider's metrics handler and returns the response body
+func scrapeMetrics(t *testing.T, provider *TracerProvider) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	provider.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("expected the Prometheus text content type, got %q", contentType)
+	}
+
+	return recorder.Body.String()
+}
+
+// TestMetricsHandler tests that the export counters are served in the Prometheus text format
+func TestMetricsHandler(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	for range 3 {
+		_, span := provider.Tracer().Start(context.Background(), "exported")
+		span.End()
+	}
+	flushSpans(t, provider, exporter)
+
+	body := scrapeMetrics(t, provider)
+
+	expected := []string{
+		"# TYPE goteletracer_spans_exported_total counter",
+		"goteletracer_spans_exported_total 3",
+		"goteletracer_spans_dropped_total 0",
+		"goteletracer_spans_fallback_exported_total 0",
+		"goteletracer_spans_export_limit_dropped_total 0",
+		"goteletracer_invalid_link_attributes_total 0",
+		"goteletracer_malformed_contexts_total 0",
+		"goteletracer_span_name_violations_total 0",
+		"# TYPE goteletracer_export_queue_spans gauge",
+		"goteletracer_export_queue_spans 0",
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
+
+// TestMetricsHandlerQueueDepth tests that spans waiting for the next batch are reported as queued
+func TestMetricsHandlerQueueDepth(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		BatchTimeout:        time.Hour,
+		TrackQueueDepth:     true,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	for range 2 {
+		_, span := provider.Tracer().Start(context.Background(), "queued")
+		span.End()
+	}
+
+	if body := scrapeMetrics(t, provider); !strings.Contains(body, "goteletracer_export_queue_spans 2\n") {
+		t.Errorf("expected 2 queued spans, got:\n%s", body)
+	}
+
+	flushSpans(t, provider, exporter)
+	if body := scrapeMetrics(t, provider); !strings.Contains(body, "goteletracer_export_queue_spans 0\n") {
+		t.Errorf("expected an empty queue after the flush, got:\n%s", body)
+	}
+}
+
+// TestMetricsHandlerQueueOverflow tests that the tracker leaves dropping spans on a full
+// queue to the batch processor, caps the depth at MaxQueueSize and reports an empty queue
+// once it drained
+func TestMetricsHandlerQueueOverflow(t *testing.T) {
+	exporter := &blockingExporter{started: make(chan struct{}, 10), release: make(chan struct{})}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		MaxQueueSize:        2,
+		MaxExportBatchSize:  2,
+		BatchTimeout:        time.Hour,
+		TrackQueueDepth:     true,
+		DisableGlobal:       true,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	endSpans := func(count int) {
+		for range count {
+			_, span := provider.Tracer().Start(context.Background(), "span")
+			span.End()
+		}
+	}
+
+	// A full batch starts an export that blocks, so the next spans overflow the queue
+	endSpans(2)
+	<-exporter.started
+	endSpans(10)
+
+	if body := scrapeMetrics(t, provider); !strings.Contains(body, "goteletracer_export_queue_spans 2\n") {
+		t.Errorf("expected the queue depth to be capped at 2 while the queue is full, got:\n%s", body)
+	}
+
+	close(exporter.release)
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if body := scrapeMetrics(t, provider); !strings.Contains(body, "goteletracer_export_queue_spans 0\n") {
+		t.Errorf("expected an empty queue after the overflow drained, got:\n%s", body)
+	}
+
+	// The batch processor exports the blocked batch and its full queue and drops the rest
+	if exported := provider.Stats().Exported; exported != 4 {
+		t.Errorf("expected 4 exported spans, got %d", exported)
+	}
+}
+
+// TestQueueDepthUntracked tests that the queue is not tracked unless TrackQueueDepth is set
+func TestQueueDepthUntracked(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		BatchTimeout:        time.Hour,
+		DisableGlobal:       true,
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	_, span := provider.Tracer().Start(context.Background(), "queued")
+	span.End()
+
+	if len(provider.queues) != 0 {
+		t.Errorf("expected no queue tracker, got %d", len(provider.queues))
+	}
+	if depth := provider.Stats().QueueDepth; depth != 0 {
+		t.Errorf("expected an untracked queue depth of 0, got %d", depth)
+	}
+}
+
+// TestMetricsHandlerNoopProvider tests that noop providers serve zero counters
+func TestMetricsHandlerNoopProvider(t *testing.T) {
+	if body := scrapeMetrics(t, NewNoopTracerProvider()); !strings.Contains(body, "goteletracer_spans_exported_total 0\n") {
+		t.Errorf("expected zero counters, got:\n%s", body)
+	}
+}
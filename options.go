@@ -0,0 +1,96 @@
+package goteletracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// providerOptions holds the overrides applied by Option functions
+type providerOptions struct {
+	sampler            sdk_trace.Sampler
+	resourceAttributes []attribute.KeyValue
+	propagator         propagation.TextMapPropagator
+	caCertFile         string
+	spanAttributes     []attribute.KeyValue
+	debugBaggageKey    string
+}
+
+// Option overrides a default of NewTracerProviderWithOptions
+type Option func(*providerOptions)
+
+// WithSampler replaces the sampler derived from the config, including
+// SpanKindSamplingRatios and InheritParentSampling
+func WithSampler(sampler sdk_trace.Sampler) Option {
+	return func(o *providerOptions) {
+		o.sampler = sampler
+	}
+}
+
+// WithDebugForcingSampler wraps the sampler so spans whose parent context carries the
+// baggage entry baggageKey=true are always sampled, e.g. to trace a single request sent
+// with "baggage: debug=true". Other spans are left to the wrapped sampler.
+func WithDebugForcingSampler(baggageKey string) Option {
+	return func(o *providerOptions) {
+		o.debugBaggageKey = baggageKey
+	}
+}
+
+// WithResourceAttributes adds attributes to the resource describing the service.
+// Attributes with the same key as a default resource attribute override it.
+func WithResourceAttributes(attributes ...attribute.KeyValue) Option {
+	return func(o *providerOptions) {
+		o.resourceAttributes = append(o.resourceAttributes, attributes...)
+	}
+}
+
+// WithPropagators replaces the default W3C trace context and baggage propagators.
+// Multiple propagators are combined into a composite propagator.
+func WithPropagators(propagators ...propagation.TextMapPropagator) Option {
+	return func(o *providerOptions) {
+		o.propagator = propagation.NewCompositeTextMapPropagator(propagators...)
+	}
+}
+
+// WithCACertFile secures the exporter connection with TLS, trusting the PEM encoded
+// CA certificates in the file, e.g. for collectors behind a private CA.
+// Other settings are taken from Config.TLS when it is set.
+func WithCACertFile(path string) Option {
+	return func(o *providerOptions) {
+		o.caCertFile = path
+	}
+}
+
+// WithDefaultSpanAttributes sets the attributes on every span started by the provider's
+// tracers, e.g. the region or instance ID. Attributes passed to Start with the same key
+// take precedence.
+func WithDefaultSpanAttributes(attributes ...attribute.KeyValue) Option {
+	return func(o *providerOptions) {
+		o.spanAttributes = append(o.spanAttributes, attributes...)
+	}
+}
+
+// attributeTracer wraps a tracer and adds default attributes to the spans it starts
+type attributeTracer struct {
+	trace.Tracer
+	attributes []attribute.KeyValue
+}
+
+// withSpanAttributes wraps the tracer in an attributeTracer when there are default attributes
+func withSpanAttributes(tracer trace.Tracer, attributes []attribute.KeyValue) trace.Tracer {
+	if len(attributes) == 0 {
+		return tracer
+	}
+
+	return &attributeTracer{Tracer: tracer, attributes: attributes}
+}
+
+// Start starts the span with the default attributes ahead of the given options,
+// so attributes set through opts override them
+func (t *attributeTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append([]trace.SpanStartOption{trace.WithAttributes(t.attributes...)}, opts...)
+	return t.Tracer.Start(ctx, name, opts...)
+}
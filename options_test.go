@@ -0,0 +1,107 @@
+package goteletracer
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestProviderWithOptions creates a provider exporting to an in-memory exporter with options
+func newTestProviderWithOptions(t *testing.T, opts ...Option) (*TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+	}, exporter, opts...)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+
+	return provider, exporter
+}
+
+// TestWithSampler tests that the sampler option replaces the default sampler
+func TestWithSampler(t *testing.T) {
+	provider, exporter := newTestProviderWithOptions(t, WithSampler(sdk_trace.NeverSample()))
+
+	_, span := provider.Tracer().Start(context.Background(), "dropped")
+	span.End()
+
+	if spans := flushSpans(t, provider, exporter); len(spans) != 0 {
+		t.Errorf("expected no spans with NeverSample, got %d", len(spans))
+	}
+}
+
+// TestWithResourceAttributes tests that the resource option adds attributes to exported spans
+func TestWithResourceAttributes(t *testing.T) {
+	provider, exporter := newTestProviderWithOptions(t, WithResourceAttributes(attribute.String("service.version", "1.2.3")))
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	resource := spans[0].Resource
+	if value, ok := resource.Set().Value("service.version"); !ok || value.AsString() != "1.2.3" {
+		t.Errorf("expected service.version resource attribute, got %v", resource.Attributes())
+	}
+	if value, ok := resource.Set().Value("service.name"); !ok || value.AsString() != "test-service" {
+		t.Errorf("expected service.name to be kept, got %v", resource.Attributes())
+	}
+}
+
+// TestWithPropagators tests that the propagators option replaces the default propagators
+func TestWithPropagators(t *testing.T) {
+	provider, _ := newTestProviderWithOptions(t, WithPropagators(propagation.Baggage{}))
+
+	fields := provider.propagator.Fields()
+	if len(fields) != 1 || fields[0] != "baggage" {
+		t.Errorf("expected only the baggage field, got %v", fields)
+	}
+}
+
+// TestWithDefaultSpanAttributes tests that default attributes are set on spans of every tracer
+func TestWithDefaultSpanAttributes(t *testing.T) {
+	provider, exporter := newTestProviderWithOptions(t, WithDefaultSpanAttributes(
+		attribute.String("cloud.region", "eu-west-1"),
+		attribute.String("service.instance.id", "instance-1"),
+	))
+
+	_, span := provider.Tracer().Start(context.Background(), "default")
+	span.End()
+
+	_, span = provider.NamedTracer("library").Start(context.Background(), "named")
+	span.End()
+
+	_, span = provider.Tracer().Start(context.Background(), "override", trace.WithAttributes(attribute.String("cloud.region", "us-east-1")))
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	expectedRegions := map[string]string{"default": "eu-west-1", "named": "eu-west-1", "override": "us-east-1"}
+	for _, span := range spans {
+		if value, _ := spanAttribute(span, "cloud.region"); value.AsString() != expectedRegions[span.Name] {
+			t.Errorf("%s: expected cloud.region %q, got %q", span.Name, expectedRegions[span.Name], value.AsString())
+		}
+		if value, _ := spanAttribute(span, "service.instance.id"); value.AsString() != "instance-1" {
+			t.Errorf("%s: expected service.instance.id attribute, got %v", span.Name, span.Attributes)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package presets provides ready-made goteletracer configurations encoding common best practices
+package presets
+
+import (
+	"crypto/tls"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fikri240794/goteletracer"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ProductionSamplingRatio is the ratio of root spans sampled by the production preset
+const ProductionSamplingRatio = 0.1
+
+// Production returns a config suited for production services.
+// Root spans are sampled at ProductionSamplingRatio, child spans follow their
+// parent's decision, spans are logged locally during collector outages, and the
+// connection to the collector uses TLS verified against the system roots and gzip.
+func Production(serviceName, endpoint string) *goteletracer.Config {
+	return &goteletracer.Config{
+		ServiceName:          serviceName,
+		ExporterGRPCAddress:  endpoint,
+		TLS:                  &tls.Config{MinVersion: tls.VersionTLS12},
+		ShutdownTimeout:      30 * time.Second,
+		Compression:          goteletracer.CompressionGzip,
+		SamplingRatio:        ProductionSamplingRatio,
+		DegradeAfterFailures: 5,
+	}
+}
+
+// Development returns a config suited for local development.
+// Every span is sampled and pretty printed to stdout as JSON as soon as it ends, so
+// no collector is needed.
+func Development(serviceName string) *goteletracer.Config {
+	return development(serviceName, os.Stdout)
+}
+
+// development returns the development config printing spans to the writer
+func development(serviceName string, w io.Writer) *goteletracer.Config {
+	cfg := &goteletracer.Config{
+		ServiceName: serviceName,
+		// Spans are printed by the stdout processor below rather than by the
+		// OTLP exporter, which would write binary protobuf
+		WriterExporter:  io.Discard,
+		SamplingRatio:   1,
+		ShutdownTimeout: 5 * time.Second,
+	}
+
+	// stdouttrace.New only fails on invalid options
+	if stdoutExporter, err := stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint()); err == nil {
+		cfg.ExtraSpanProcessors = []sdk_trace.SpanProcessor{sdk_trace.NewSimpleSpanProcessor(stdoutExporter)}
+	}
+
+	return cfg
+}
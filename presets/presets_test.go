@@ -0,0 +1,83 @@
+package presets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fikri240794/goteletracer"
+)
+
+// TestProduction tests the production preset
+func TestProduction(t *testing.T) {
+	cfg := Production("orders", "collector:4317")
+
+	if cfg.ServiceName != "orders" || cfg.ExporterGRPCAddress != "collector:4317" {
+		t.Errorf("unexpected service or endpoint: %q, %q", cfg.ServiceName, cfg.ExporterGRPCAddress)
+	}
+	if cfg.TLS == nil {
+		t.Error("expected a TLS secured collector connection")
+	}
+	if cfg.SamplingRatio != ProductionSamplingRatio {
+		t.Errorf("expected sampling ratio %v, got %v", ProductionSamplingRatio, cfg.SamplingRatio)
+	}
+
+	if _, _, err := goteletracer.Lint(cfg); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+}
+
+// TestDevelopment tests the development preset
+func TestDevelopment(t *testing.T) {
+	cfg := Development("orders")
+
+	if cfg.ServiceName != "orders" {
+		t.Errorf("unexpected service name %q", cfg.ServiceName)
+	}
+	if cfg.ExporterGRPCAddress != "" || cfg.WriterExporter == nil {
+		t.Error("expected no collector to be needed")
+	}
+	if len(cfg.ExtraSpanProcessors) != 1 {
+		t.Errorf("expected a stdout span processor, got %d processors", len(cfg.ExtraSpanProcessors))
+	}
+	if cfg.SamplingRatio != 1 || len(cfg.SpanKindSamplingRatios) != 0 {
+		t.Error("expected every span to be sampled")
+	}
+
+	if _, _, err := goteletracer.Lint(cfg); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+}
+
+// TestDevelopmentOutput tests that the development preset prints readable spans
+func TestDevelopmentOutput(t *testing.T) {
+	var output bytes.Buffer
+	cfg := development("orders", &output)
+	cfg.DisableGlobal = true
+
+	provider, err := goteletracer.NewTracerProvider(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "checkout")
+	span.End()
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+
+	printedOutput := output.String()
+	var printed struct{ Name string }
+	if err := json.NewDecoder(&output).Decode(&printed); err != nil {
+		t.Fatalf("expected the span printed as JSON, got %v: %q", err, printedOutput)
+	}
+	if printed.Name != "checkout" {
+		t.Errorf("expected the checkout span, got %q", printed.Name)
+	}
+	if !strings.Contains(printedOutput, "\n\t") {
+		t.Error("expected the span to be pretty printed")
+	}
+}
@@ -0,0 +1,636 @@
+package goteletracer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GoroutineIDKey is the span attribute key holding the best-effort goroutine identifier
+const GoroutineIDKey = attribute.Key("goroutine.id")
+
+// goroutineIDProcessor sets the identifier of the goroutine that started a span.
+// OnStart is called synchronously by the goroutine calling Start, so the
+// identifier reflects the worker that produced the span.
+type goroutineIDProcessor struct{}
+
+// OnStart records the current goroutine identifier on the span
+func (goroutineIDProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	if id, ok := goroutineID(); ok {
+		s.SetAttributes(GoroutineIDKey.Int64(id))
+	}
+}
+
+// OnEnd does nothing
+func (goroutineIDProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (goroutineIDProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (goroutineIDProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// goroutineID parses the current goroutine identifier from the runtime stack header
+// ("goroutine 42 [running]:"). Go deliberately hides goroutine IDs, so this is a
+// best-effort value intended for debugging only.
+func goroutineID() (int64, bool) {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0, false
+	}
+
+	end := bytes.IndexByte(stack, ' ')
+	if end < 0 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(string(stack[:end]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// OverflowSpanName replaces novel span names once MaxDistinctSpanNames is exceeded
+const OverflowSpanName = "other"
+
+// spanNameLimitProcessor bounds the number of distinct span names. Once the limit
+// is reached, spans with previously unseen names are renamed to OverflowSpanName.
+type spanNameLimitProcessor struct {
+	limit  int
+	logger Logger
+
+	mu     sync.Mutex
+	names  map[string]struct{}
+	capped bool
+}
+
+// newSpanNameLimitProcessor creates a spanNameLimitProcessor with the given limit
+func newSpanNameLimitProcessor(limit int, logger Logger) *spanNameLimitProcessor {
+	return &spanNameLimitProcessor{
+		limit:  limit,
+		logger: logger,
+		names:  make(map[string]struct{}, limit),
+	}
+}
+
+// OnStart records the span name or rewrites it when the limit has been reached
+func (p *spanNameLimitProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	name := s.Name()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.names[name]; ok {
+		return
+	}
+
+	if len(p.names) < p.limit {
+		p.names[name] = struct{}{}
+		return
+	}
+
+	if !p.capped {
+		p.capped = true
+		p.logger.Printf("goteletracer: distinct span name limit of %d reached, renaming %q and further new names to %q", p.limit, name, OverflowSpanName)
+	}
+
+	s.SetName(OverflowSpanName)
+}
+
+// OnEnd does nothing
+func (p *spanNameLimitProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (p *spanNameLimitProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *spanNameLimitProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// spanNameConventionProcessor checks span names against a naming convention at
+// Start, counting violations and optionally rewriting names with a normalizer
+type spanNameConventionProcessor struct {
+	validator  func(name string) bool
+	normalizer func(name string) string
+	violations atomic.Int64
+}
+
+// newSpanNameConventionProcessor creates a spanNameConventionProcessor; either function may be nil
+func newSpanNameConventionProcessor(validator func(name string) bool, normalizer func(name string) string) *spanNameConventionProcessor {
+	return &spanNameConventionProcessor{
+		validator:  validator,
+		normalizer: normalizer,
+	}
+}
+
+// OnStart counts non-conforming span names and rewrites them when a normalizer is set
+func (p *spanNameConventionProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	name := s.Name()
+
+	if p.validator != nil {
+		if p.validator(name) {
+			return
+		}
+		p.violations.Add(1)
+	}
+
+	if p.normalizer != nil {
+		s.SetName(p.normalizer(name))
+	}
+}
+
+// OnEnd does nothing
+func (p *spanNameConventionProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (p *spanNameConventionProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *spanNameConventionProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// DefaultRequestIDKey is the default span attribute key holding the request ID
+const DefaultRequestIDKey = attribute.Key("request.id")
+
+// requestIDProcessor copies the request ID stored by WithRequestID onto spans
+type requestIDProcessor struct {
+	key attribute.Key
+}
+
+// OnStart sets the request ID from the parent context on the span
+func (p requestIDProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	if id := RequestIDFromContext(parent); id != "" {
+		s.SetAttributes(p.key.String(id))
+	}
+}
+
+// OnEnd does nothing
+func (p requestIDProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (p requestIDProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p requestIDProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// attributesSpan is a ReadOnlySpan with a replaced set of attributes
+type attributesSpan struct {
+	sdk_trace.ReadOnlySpan
+	attributes []attribute.KeyValue
+}
+
+// Attributes returns the replaced attributes
+func (s *attributesSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+// dropAttributesProcessor wraps the exporting span processor and removes
+// configured attribute keys from spans before passing them on at OnEnd.
+// Keys ending with "*" match every attribute key with that prefix.
+type dropAttributesProcessor struct {
+	sdk_trace.SpanProcessor
+	exact    map[attribute.Key]struct{}
+	prefixes []string
+}
+
+// newDropAttributesProcessor creates a dropAttributesProcessor around the next processor
+func newDropAttributesProcessor(next sdk_trace.SpanProcessor, keys []string) *dropAttributesProcessor {
+	p := &dropAttributesProcessor{
+		SpanProcessor: next,
+		exact:         make(map[attribute.Key]struct{}, len(keys)),
+	}
+
+	for _, key := range keys {
+		if prefix, ok := strings.CutSuffix(key, "*"); ok {
+			p.prefixes = append(p.prefixes, prefix)
+			continue
+		}
+		p.exact[attribute.Key(key)] = struct{}{}
+	}
+
+	return p
+}
+
+// OnEnd removes the configured attributes and hands the span to the next processor
+func (p *dropAttributesProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	attributes := s.Attributes()
+	kept := make([]attribute.KeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		if !p.dropped(attr.Key) {
+			kept = append(kept, attr)
+		}
+	}
+
+	if len(kept) == len(attributes) {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+
+	p.SpanProcessor.OnEnd(&attributesSpan{ReadOnlySpan: s, attributes: kept})
+}
+
+// dropped reports whether the attribute key matches an exact key or prefix
+func (p *dropAttributesProcessor) dropped(key attribute.Key) bool {
+	if _, ok := p.exact[key]; ok {
+		return true
+	}
+
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(string(key), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sanitizedSpan is a ReadOnlySpan with a replaced name and set of attributes
+type sanitizedSpan struct {
+	sdk_trace.ReadOnlySpan
+	name       string
+	attributes []attribute.KeyValue
+}
+
+// Name returns the replaced name
+func (s *sanitizedSpan) Name() string {
+	return s.name
+}
+
+// Attributes returns the replaced attributes
+func (s *sanitizedSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+// sanitizeProcessor wraps the exporting span processor and rewrites span names and
+// attributes at OnEnd, e.g. to strip IDs or emails before they reach the collector
+type sanitizeProcessor struct {
+	sdk_trace.SpanProcessor
+	nameSanitizer      func(string) string
+	attributeSanitizer func(attribute.KeyValue) attribute.KeyValue
+}
+
+// newSanitizeProcessor creates a sanitizeProcessor around the next processor.
+// Either sanitizer may be nil.
+func newSanitizeProcessor(next sdk_trace.SpanProcessor, nameSanitizer func(string) string, attributeSanitizer func(attribute.KeyValue) attribute.KeyValue) *sanitizeProcessor {
+	return &sanitizeProcessor{
+		SpanProcessor:      next,
+		nameSanitizer:      nameSanitizer,
+		attributeSanitizer: attributeSanitizer,
+	}
+}
+
+// OnEnd rewrites the span name and attributes and hands the span to the next processor
+func (p *sanitizeProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	name := s.Name()
+	if p.nameSanitizer != nil {
+		name = p.nameSanitizer(name)
+	}
+
+	attributes := s.Attributes()
+	if p.attributeSanitizer != nil {
+		sanitized := make([]attribute.KeyValue, len(attributes))
+		for i, attr := range attributes {
+			sanitized[i] = p.attributeSanitizer(attr)
+		}
+		attributes = sanitized
+	}
+
+	p.SpanProcessor.OnEnd(&sanitizedSpan{ReadOnlySpan: s, name: name, attributes: attributes})
+}
+
+// preExportProcessor wraps the exporting span processor and calls other processors
+// before it, so their OnStart and OnEnd complete before the span is handed to export
+type preExportProcessor struct {
+	next       sdk_trace.SpanProcessor
+	processors []sdk_trace.SpanProcessor
+}
+
+// newPreExportProcessor creates a preExportProcessor calling processors before next
+func newPreExportProcessor(next sdk_trace.SpanProcessor, processors []sdk_trace.SpanProcessor) *preExportProcessor {
+	return &preExportProcessor{
+		next:       next,
+		processors: processors,
+	}
+}
+
+// OnStart calls OnStart of the processors in order, then of the next processor
+func (p *preExportProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	for _, processor := range p.processors {
+		processor.OnStart(parent, s)
+	}
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd calls OnEnd of the processors in order, then of the next processor
+func (p *preExportProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	for _, processor := range p.processors {
+		processor.OnEnd(s)
+	}
+	p.next.OnEnd(s)
+}
+
+// Shutdown shuts down the processors, then the next processor, joining their errors
+func (p *preExportProcessor) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, processor := range p.processors {
+		errs = append(errs, processor.Shutdown(ctx))
+	}
+
+	return errors.Join(append(errs, p.next.Shutdown(ctx))...)
+}
+
+// ForceFlush flushes the processors, then the next processor, joining their errors
+func (p *preExportProcessor) ForceFlush(ctx context.Context) error {
+	var errs []error
+	for _, processor := range p.processors {
+		errs = append(errs, processor.ForceFlush(ctx))
+	}
+
+	return errors.Join(append(errs, p.next.ForceFlush(ctx))...)
+}
+
+// BudgetDroppedAttributesKey is the span attribute key holding the number of attributes
+// removed to stay within MaxSpanAttributeBytes
+const BudgetDroppedAttributesKey = attribute.Key("goteletracer.budget_dropped_attributes")
+
+// attributeBudgetProcessor wraps the exporting span processor and drops the largest
+// attributes of a span until their estimated serialized size fits the budget
+type attributeBudgetProcessor struct {
+	sdk_trace.SpanProcessor
+	budget int
+}
+
+// newAttributeBudgetProcessor creates an attributeBudgetProcessor around the next processor
+func newAttributeBudgetProcessor(next sdk_trace.SpanProcessor, budget int) *attributeBudgetProcessor {
+	return &attributeBudgetProcessor{
+		SpanProcessor: next,
+		budget:        budget,
+	}
+}
+
+// OnEnd enforces the attribute budget and hands the span to the next processor
+func (p *attributeBudgetProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	attributes := s.Attributes()
+
+	sizes := make([]int, len(attributes))
+	total := 0
+	for i, attr := range attributes {
+		sizes[i] = attributeSize(attr)
+		total += sizes[i]
+	}
+
+	if total <= p.budget {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+
+	// Drop the largest attributes first to remove as few as possible
+	order := make([]int, len(attributes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return sizes[order[i]] > sizes[order[j]] })
+
+	removed := make([]bool, len(attributes))
+	dropped := 0
+	for _, i := range order {
+		if total <= p.budget {
+			break
+		}
+		removed[i] = true
+		total -= sizes[i]
+		dropped++
+	}
+
+	kept := make([]attribute.KeyValue, 0, len(attributes)-dropped+1)
+	for i, attr := range attributes {
+		if !removed[i] {
+			kept = append(kept, attr)
+		}
+	}
+	kept = append(kept, BudgetDroppedAttributesKey.Int(dropped))
+
+	p.SpanProcessor.OnEnd(&attributesSpan{ReadOnlySpan: s, attributes: kept})
+}
+
+// attributeSize estimates the serialized size of an attribute in bytes
+func attributeSize(attr attribute.KeyValue) int {
+	size := len(attr.Key)
+
+	switch attr.Value.Type() {
+	case attribute.BOOL:
+		size++
+	case attribute.INT64, attribute.FLOAT64:
+		size += 8
+	case attribute.STRING:
+		size += len(attr.Value.AsString())
+	case attribute.BOOLSLICE:
+		size += len(attr.Value.AsBoolSlice())
+	case attribute.INT64SLICE:
+		size += 8 * len(attr.Value.AsInt64Slice())
+	case attribute.FLOAT64SLICE:
+		size += 8 * len(attr.Value.AsFloat64Slice())
+	case attribute.STRINGSLICE:
+		for _, value := range attr.Value.AsStringSlice() {
+			size += len(value)
+		}
+	}
+
+	return size
+}
+
+// linkAttributesProcessor turns span start attributes holding hex encoded upstream
+// trace and span IDs into span links
+type linkAttributesProcessor struct {
+	keys    map[attribute.Key]attribute.Key
+	logger  Logger
+	invalid atomic.Int64
+}
+
+// newLinkAttributesProcessor creates a linkAttributesProcessor from a mapping of
+// trace ID attribute keys to span ID attribute keys
+func newLinkAttributesProcessor(keys map[string]string, logger Logger) *linkAttributesProcessor {
+	p := &linkAttributesProcessor{
+		keys:   make(map[attribute.Key]attribute.Key, len(keys)),
+		logger: logger,
+	}
+
+	for traceIDKey, spanIDKey := range keys {
+		p.keys[attribute.Key(traceIDKey)] = attribute.Key(spanIDKey)
+	}
+
+	return p
+}
+
+// OnStart adds a link for every configured trace and span ID attribute pair
+func (p *linkAttributesProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	attributes := attribute.NewSet(s.Attributes()...)
+
+	for traceIDKey, spanIDKey := range p.keys {
+		traceIDValue, hasTraceID := attributes.Value(traceIDKey)
+		spanIDValue, hasSpanID := attributes.Value(spanIDKey)
+		if !hasTraceID && !hasSpanID {
+			continue
+		}
+
+		spanContext, ok := linkSpanContext(traceIDValue, spanIDValue)
+		if !ok {
+			p.invalid.Add(1)
+			p.logger.Printf("goteletracer: skipping link from %q/%q on span %q: invalid trace or span id", traceIDKey, spanIDKey, s.Name())
+			continue
+		}
+
+		s.AddLink(trace.Link{SpanContext: spanContext})
+	}
+}
+
+// linkSpanContext returns the remote span context of hex encoded trace and span ID values
+func linkSpanContext(traceIDValue, spanIDValue attribute.Value) (trace.SpanContext, bool) {
+	traceID, traceErr := trace.TraceIDFromHex(traceIDValue.AsString())
+	spanID, spanErr := trace.SpanIDFromHex(spanIDValue.AsString())
+	if traceErr != nil || spanErr != nil {
+		return trace.SpanContext{}, false
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	}), true
+}
+
+// OnEnd does nothing
+func (p *linkAttributesProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (p *linkAttributesProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *linkAttributesProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// linkedAttributesProcessor wraps the exporting span processor and removes the
+// LinkAttributeKeys pairs that were turned into links at OnEnd, so the IDs are exported
+// once, as the link. Pairs without a matching link, e.g. invalid IDs, are kept.
+type linkedAttributesProcessor struct {
+	sdk_trace.SpanProcessor
+	keys map[attribute.Key]attribute.Key
+}
+
+// newLinkedAttributesProcessor creates a linkedAttributesProcessor around the next processor
+// from a mapping of trace ID attribute keys to span ID attribute keys
+func newLinkedAttributesProcessor(next sdk_trace.SpanProcessor, keys map[string]string) *linkedAttributesProcessor {
+	p := &linkedAttributesProcessor{
+		SpanProcessor: next,
+		keys:          make(map[attribute.Key]attribute.Key, len(keys)),
+	}
+
+	for traceIDKey, spanIDKey := range keys {
+		p.keys[attribute.Key(traceIDKey)] = attribute.Key(spanIDKey)
+	}
+
+	return p
+}
+
+// OnEnd removes the ID attributes of the pairs found among the span links and hands the
+// span to the next processor
+func (p *linkedAttributesProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	links := s.Links()
+	if len(links) == 0 {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+
+	attributes := s.Attributes()
+	set := attribute.NewSet(attributes...)
+	linked := make(map[attribute.Key]struct{})
+	for traceIDKey, spanIDKey := range p.keys {
+		traceIDValue, hasTraceID := set.Value(traceIDKey)
+		spanIDValue, hasSpanID := set.Value(spanIDKey)
+		if !hasTraceID || !hasSpanID {
+			continue
+		}
+
+		spanContext, ok := linkSpanContext(traceIDValue, spanIDValue)
+		if !ok {
+			continue
+		}
+		for _, link := range links {
+			if link.SpanContext.TraceID() == spanContext.TraceID() && link.SpanContext.SpanID() == spanContext.SpanID() {
+				linked[traceIDKey] = struct{}{}
+				linked[spanIDKey] = struct{}{}
+				break
+			}
+		}
+	}
+
+	if len(linked) == 0 {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+
+	kept := make([]attribute.KeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		if _, ok := linked[attr.Key]; !ok {
+			kept = append(kept, attr)
+		}
+	}
+
+	p.SpanProcessor.OnEnd(&attributesSpan{ReadOnlySpan: s, attributes: kept})
+}
+
+// malformedContextProcessor marks spans started from a context flagged by a failed
+// trace context extraction
+type malformedContextProcessor struct{}
+
+// OnStart sets the malformed parent context attribute when the parent context is flagged
+func (malformedContextProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	if IsMalformedContext(parent) {
+		s.SetAttributes(MalformedParentContextKey.Bool(true))
+	}
+}
+
+// OnEnd does nothing
+func (malformedContextProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {}
+
+// Shutdown does nothing
+func (malformedContextProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (malformedContextProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,537 @@
+package goteletracer
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestGoroutineID tests that the parsed goroutine identifier differs between goroutines
+func TestGoroutineID(t *testing.T) {
+	mainID, ok := goroutineID()
+	if !ok || mainID <= 0 {
+		t.Fatalf("expected a positive goroutine id, got %d (ok=%v)", mainID, ok)
+	}
+
+	var otherID int64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		otherID, _ = goroutineID()
+	}()
+	wg.Wait()
+
+	if otherID == mainID {
+		t.Errorf("expected different ids for different goroutines, both were %d", mainID)
+	}
+}
+
+// TestGoroutineIDProcessor tests that the goroutine id attribute is only set when enabled
+func TestGoroutineIDProcessor(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		expectKey bool
+	}{
+		{name: "disabled by default", enabled: false, expectKey: false},
+		{name: "enabled", enabled: true, expectKey: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				RecordGoroutineID:   tt.enabled,
+			})
+
+			_, span := provider.Tracer().Start(context.Background(), "worker")
+			span.End()
+
+			if err := provider.provider.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("expected no flush error, got %v", err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			found := false
+			for _, attr := range spans[0].Attributes {
+				if attr.Key == GoroutineIDKey && attr.Value.AsInt64() > 0 {
+					found = true
+				}
+			}
+			if found != tt.expectKey {
+				t.Errorf("expected goroutine id attribute present=%v, got %v", tt.expectKey, found)
+			}
+		})
+	}
+}
+
+// TestSpanNameLimitProcessor tests that novel names are bucketed once the limit is hit
+func TestSpanNameLimitProcessor(t *testing.T) {
+	logger := &captureLogger{}
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:          "test-service",
+		ExporterGRPCAddress:  "localhost:4317",
+		Logger:               logger,
+		MaxDistinctSpanNames: 2,
+	})
+
+	tracer := provider.Tracer()
+	for _, name := range []string{"a", "b", "c", "a", "d", "b"} {
+		_, span := tracer.Start(context.Background(), name)
+		span.End()
+	}
+
+	if err := provider.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	expected := []string{"a", "b", OverflowSpanName, "a", OverflowSpanName, "b"}
+	spans := exporter.GetSpans()
+	if len(spans) != len(expected) {
+		t.Fatalf("expected %d spans, got %d", len(expected), len(spans))
+	}
+	for i, span := range spans {
+		if span.Name != expected[i] {
+			t.Errorf("span %d: expected name %q, got %q", i, expected[i], span.Name)
+		}
+	}
+
+	if logger.count() != 1 {
+		t.Errorf("expected the cap to be logged once, got %d messages", logger.count())
+	}
+}
+
+// TestSpanNameConventionProcessor tests counting and rewriting of non-conforming span names
+func TestSpanNameConventionProcessor(t *testing.T) {
+	validator := func(name string) bool {
+		verb, noun, ok := strings.Cut(name, ".")
+		return ok && verb != "" && noun != ""
+	}
+	normalizer := func(name string) string {
+		return "handle." + strings.ToLower(name)
+	}
+
+	tests := []struct {
+		name               string
+		normalizer         func(string) string
+		expectedNames      []string
+		expectedViolations int64
+	}{
+		{
+			name:               "validate only",
+			normalizer:         nil,
+			expectedNames:      []string{"get.user", "GetUser", "save"},
+			expectedViolations: 2,
+		},
+		{
+			name:               "validate and rewrite",
+			normalizer:         normalizer,
+			expectedNames:      []string{"get.user", "handle.getuser", "handle.save"},
+			expectedViolations: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SpanNameValidator:   validator,
+				SpanNameNormalizer:  tt.normalizer,
+			})
+
+			for _, name := range []string{"get.user", "GetUser", "save"} {
+				_, span := provider.Tracer().Start(context.Background(), name)
+				span.End()
+			}
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != len(tt.expectedNames) {
+				t.Fatalf("expected %d spans, got %d", len(tt.expectedNames), len(spans))
+			}
+			for i, span := range spans {
+				if span.Name != tt.expectedNames[i] {
+					t.Errorf("span %d: expected name %q, got %q", i, tt.expectedNames[i], span.Name)
+				}
+			}
+
+			if got := provider.Stats().SpanNameViolations; got != tt.expectedViolations {
+				t.Errorf("expected %d violations, got %d", tt.expectedViolations, got)
+			}
+		})
+	}
+}
+
+// TestDropAttributesProcessor tests that configured keys are absent from exported spans
+func TestDropAttributesProcessor(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		DropAttributeKeys:   []string{"internal.ip", "net.host.*"},
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "request")
+	span.SetAttributes(
+		attribute.String("internal.ip", "10.0.0.1"),
+		attribute.String("net.host.name", "node-1"),
+		attribute.Int("net.host.port", 8080),
+		attribute.String("http.method", "GET"),
+		attribute.String("internal.ip.version", "v4"),
+	)
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	for _, key := range []attribute.Key{"internal.ip", "net.host.name", "net.host.port"} {
+		if _, ok := spanAttribute(spans[0], key); ok {
+			t.Errorf("expected %q to be dropped", key)
+		}
+	}
+	for _, key := range []attribute.Key{"http.method", "internal.ip.version"} {
+		if _, ok := spanAttribute(spans[0], key); !ok {
+			t.Errorf("expected %q to be kept", key)
+		}
+	}
+}
+
+// TestSanitizeProcessor tests that span names and attributes are rewritten on export only
+func TestSanitizeProcessor(t *testing.T) {
+	digits := regexp.MustCompile(`[0-9]+`)
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		RetainSpans:         1,
+		SpanNameSanitizer: func(name string) string {
+			return digits.ReplaceAllString(name, "{id}")
+		},
+		AttributeSanitizer: func(attr attribute.KeyValue) attribute.KeyValue {
+			if attr.Key == "user.email" {
+				return attr.Key.String("redacted")
+			}
+			return attr
+		},
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "GET /users/42")
+	span.SetAttributes(
+		attribute.String("user.email", "jane@example.com"),
+		attribute.Int("items", 3),
+	)
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "GET /users/{id}" {
+		t.Errorf("expected sanitized span name, got %q", spans[0].Name)
+	}
+	if value, _ := spanAttribute(spans[0], "user.email"); value.AsString() != "redacted" {
+		t.Errorf("expected sanitized email, got %q", value.AsString())
+	}
+	if value, _ := spanAttribute(spans[0], "items"); value.AsInt64() != 3 {
+		t.Errorf("expected other attributes to be kept, got %v", spans[0].Attributes)
+	}
+
+	if retained := provider.recorder.Spans(); len(retained) != 1 || retained[0].Name() != "GET /users/42" {
+		t.Errorf("expected retained spans to keep the original name, got %v", retained)
+	}
+}
+
+// TestAttributeSize tests the estimated serialized size of attributes
+func TestAttributeSize(t *testing.T) {
+	tests := []struct {
+		attr     attribute.KeyValue
+		expected int
+	}{
+		{attr: attribute.Bool("ok", true), expected: 3},
+		{attr: attribute.Int("n", 1), expected: 9},
+		{attr: attribute.Float64("f", 1.5), expected: 9},
+		{attr: attribute.String("key", "value"), expected: 8},
+		{attr: attribute.StringSlice("tags", []string{"a", "bc"}), expected: 7},
+		{attr: attribute.Int64Slice("ids", []int64{1, 2}), expected: 19},
+	}
+
+	for _, tt := range tests {
+		if got := attributeSize(tt.attr); got != tt.expected {
+			t.Errorf("%s: expected size %d, got %d", tt.attr.Key, tt.expected, got)
+		}
+	}
+}
+
+// TestAttributeBudgetProcessor tests that the largest attributes are dropped to fit the budget
+func TestAttributeBudgetProcessor(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:           "test-service",
+		ExporterGRPCAddress:   "localhost:4317",
+		MaxSpanAttributeBytes: 50,
+	})
+
+	tracer := provider.Tracer()
+	_, small := tracer.Start(context.Background(), "small")
+	small.SetAttributes(attribute.String("a", "1"))
+	small.End()
+
+	_, large := tracer.Start(context.Background(), "large")
+	large.SetAttributes(
+		attribute.String("payload", strings.Repeat("x", 100)),
+		attribute.String("body", strings.Repeat("y", 30)),
+		attribute.String("method", "GET"),
+	)
+	large.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	if _, ok := spanAttribute(spans[0], BudgetDroppedAttributesKey); ok {
+		t.Error("expected no dropped attribute count within budget")
+	}
+
+	for _, key := range []attribute.Key{"body", "method"} {
+		if _, ok := spanAttribute(spans[1], key); !ok {
+			t.Errorf("expected %q to be kept", key)
+		}
+	}
+	if _, ok := spanAttribute(spans[1], "payload"); ok {
+		t.Error("expected the largest attribute to be dropped")
+	}
+	if value, ok := spanAttribute(spans[1], BudgetDroppedAttributesKey); !ok || value.AsInt64() != 1 {
+		t.Errorf("expected 1 dropped attribute recorded, got %v", value)
+	}
+}
+
+// TestLinkAttributesProcessor tests that ID attributes become links in place of the attributes
+// and invalid IDs are counted
+func TestLinkAttributesProcessor(t *testing.T) {
+	logger := &captureLogger{}
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		Logger:              logger,
+		LinkAttributeKeys:   map[string]string{"upstream.trace_id": "upstream.span_id"},
+	})
+
+	const traceID = "0102030405060708090a0b0c0d0e0f10"
+	const spanID = "0102030405060708"
+
+	tracer := provider.Tracer()
+	_, linked := tracer.Start(context.Background(), "linked", trace.WithAttributes(
+		attribute.String("upstream.trace_id", traceID),
+		attribute.String("upstream.span_id", spanID),
+	))
+	linked.End()
+
+	_, invalid := tracer.Start(context.Background(), "invalid", trace.WithAttributes(
+		attribute.String("upstream.trace_id", "not-hex"),
+		attribute.String("upstream.span_id", spanID),
+	))
+	invalid.End()
+
+	_, plain := tracer.Start(context.Background(), "plain")
+	plain.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	if len(spans[0].Links) != 1 {
+		t.Fatalf("expected 1 link on the linked span, got %d", len(spans[0].Links))
+	}
+	link := spans[0].Links[0].SpanContext
+	if link.TraceID().String() != traceID || link.SpanID().String() != spanID {
+		t.Errorf("unexpected link %s/%s", link.TraceID(), link.SpanID())
+	}
+
+	for _, key := range []attribute.Key{"upstream.trace_id", "upstream.span_id"} {
+		if _, ok := spanAttribute(spans[0], key); ok {
+			t.Errorf("expected %s to be removed once turned into a link", key)
+		}
+		if _, ok := spanAttribute(spans[1], key); !ok {
+			t.Errorf("expected %s to be kept when the link was skipped", key)
+		}
+	}
+
+	if len(spans[1].Links) != 0 || len(spans[2].Links) != 0 {
+		t.Error("expected no links on invalid or plain spans")
+	}
+
+	if got := provider.Stats().InvalidLinkAttributes; got != 1 {
+		t.Errorf("expected 1 invalid link attribute pair, got %d", got)
+	}
+	if logger.count() != 1 {
+		t.Errorf("expected 1 warning, got %d", logger.count())
+	}
+}
+
+// countingProcessor is a span processor counting its calls
+type countingProcessor struct {
+	mu        sync.Mutex
+	started   int
+	ended     []string
+	shutdowns int
+}
+
+// OnStart counts started spans and tags them with a correlation ID
+func (p *countingProcessor) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.started++
+	s.SetAttributes(attribute.String("correlation.id", "corr-1"))
+}
+
+// OnEnd records the names of ended spans
+func (p *countingProcessor) OnEnd(s sdk_trace.ReadOnlySpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ended = append(p.ended, s.Name())
+}
+
+// Shutdown counts shutdowns
+func (p *countingProcessor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.shutdowns++
+	return nil
+}
+
+// ForceFlush does nothing
+func (p *countingProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// TestExtraSpanProcessors tests that custom processors see every span and are shut down
+func TestExtraSpanProcessors(t *testing.T) {
+	processor := &countingProcessor{}
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		ExtraSpanProcessors: []sdk_trace.SpanProcessor{processor},
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if value, _ := spanAttribute(spans[0], "correlation.id"); value.AsString() != "corr-1" {
+		t.Errorf("expected the correlation id set in OnStart, got %v", spans[0].Attributes)
+	}
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	if processor.started != 1 || len(processor.ended) != 1 || processor.ended[0] != "operation" {
+		t.Errorf("expected OnStart and OnEnd for the span, got %d starts and %v", processor.started, processor.ended)
+	}
+	if processor.shutdowns != 1 {
+		t.Errorf("expected the processor to be shut down once, got %d", processor.shutdowns)
+	}
+}
+
+// endedBeforeExportExporter records, for every exported span, whether the processor
+// had already seen it end
+type endedBeforeExportExporter struct {
+	*tracetest.InMemoryExporter
+	processor *countingProcessor
+	seen      []bool
+}
+
+// ExportSpans records whether the processor saw each span end, then exports them
+func (e *endedBeforeExportExporter) ExportSpans(ctx context.Context, spans []sdk_trace.ReadOnlySpan) error {
+	e.processor.mu.Lock()
+	for _, span := range spans {
+		e.seen = append(e.seen, slices.Contains(e.processor.ended, span.Name()))
+	}
+	e.processor.mu.Unlock()
+
+	return e.InMemoryExporter.ExportSpans(ctx, spans)
+}
+
+// TestPreExportSpanProcessors tests that pre-export processors run before the span is
+// exported while extra processors run after it
+func TestPreExportSpanProcessors(t *testing.T) {
+	tests := []struct {
+		name              string
+		preExport         bool
+		expectEndedBefore bool
+	}{
+		{name: "pre-export", preExport: true, expectEndedBefore: true},
+		{name: "extra", preExport: false, expectEndedBefore: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := &countingProcessor{}
+			cfg := &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				// Export synchronously in OnEnd so the processor order is observable
+				UseSimpleProcessor: true,
+			}
+			if tt.preExport {
+				cfg.PreExportSpanProcessors = []sdk_trace.SpanProcessor{processor}
+			} else {
+				cfg.ExtraSpanProcessors = []sdk_trace.SpanProcessor{processor}
+			}
+
+			exporter := &endedBeforeExportExporter{InMemoryExporter: tracetest.NewInMemoryExporter(), processor: processor}
+			provider, err := newTracerProvider(context.Background(), cfg, exporter)
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			if value, _ := spanAttribute(spans[0], "correlation.id"); value.AsString() != "corr-1" {
+				t.Errorf("expected the enrichment attribute on the exported span, got %v", spans[0].Attributes)
+			}
+			if exporter.seen[0] != tt.expectEndedBefore {
+				t.Errorf("expected the processor OnEnd before export to be %v", tt.expectEndedBefore)
+			}
+
+			if err := provider.Shutdown(context.Background()); err != nil {
+				t.Fatalf("expected no shutdown error, got %v", err)
+			}
+			if processor.shutdowns != 1 {
+				t.Errorf("expected the processor to be shut down once, got %d", processor.shutdowns)
+			}
+		})
+	}
+}
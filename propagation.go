@@ -0,0 +1,189 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataCarrier adapts gRPC metadata to propagation.TextMapCarrier
+type MetadataCarrier metadata.MD
+
+// compile time check that MetadataCarrier implements propagation.TextMapCarrier
+var _ propagation.TextMapCarrier = MetadataCarrier{}
+
+// Get returns the first value associated with the key
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// Set replaces the values associated with the key
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys returns all keys stored in the carrier
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Propagator names accepted by Config.Propagators
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorJaeger       = "jaeger"
+)
+
+// ErrUnknownPropagator is returned when Config.Propagators holds an unsupported name
+var ErrUnknownPropagator = errors.New("unknown propagator")
+
+// newPropagator builds a composite propagator from propagator names, defaulting
+// to W3C trace context and baggage when no name is given
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	if len(names) == 0 {
+		names = []string{PropagatorTraceContext, PropagatorBaggage}
+	}
+
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New())
+		case PropagatorJaeger:
+			propagators = append(propagators, jaeger.Jaeger{})
+		default:
+			return nil, fmt.Errorf("%w %q", ErrUnknownPropagator, name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// InjectMetadata writes the trace context from ctx into the gRPC metadata
+// using the global text map propagator
+func InjectMetadata(ctx context.Context, md metadata.MD) {
+	otel.GetTextMapPropagator().Inject(ctx, MetadataCarrier(md))
+}
+
+// ExtractMetadata returns a copy of ctx carrying the trace context read from
+// the gRPC metadata using the global text map propagator
+func ExtractMetadata(ctx context.Context, md metadata.MD) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, MetadataCarrier(md))
+}
+
+// Propagator returns the propagators the provider was configured with, from Config.Propagators
+// or WithPropagators, independently of the global propagator. Disabled providers return
+// the global one.
+func (tp *TracerProvider) Propagator() propagation.TextMapPropagator {
+	return tp.textMapPropagator()
+}
+
+// Inject writes the trace context and baggage from ctx into the carrier using the
+// provider's propagators rather than the global one, e.g. into message queue headers
+func (tp *TracerProvider) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	tp.textMapPropagator().Inject(ctx, carrier)
+}
+
+// Extract returns a copy of ctx carrying the trace context and baggage read from the
+// carrier using the provider's propagators rather than the global one
+func (tp *TracerProvider) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return tp.textMapPropagator().Extract(ctx, carrier)
+}
+
+// MalformedParentContextKey is the span attribute key marking spans started from a
+// context whose incoming trace headers could not be extracted
+const MalformedParentContextKey = attribute.Key("goteletracer.malformed_parent_context")
+
+// baggageField is the header used by the baggage propagator, which carries no trace context
+const baggageField = "baggage"
+
+// malformedContextKey is the context key flagging a failed trace context extraction
+type malformedContextKey struct{}
+
+// checkedPropagator wraps a TextMapPropagator and detects extractions where trace
+// headers were present but did not yield a valid span context. The default behavior
+// of starting a new trace is kept; failures are counted, optionally logged and
+// flagged in the context so spans can be marked.
+type checkedPropagator struct {
+	propagation.TextMapPropagator
+	logger    Logger
+	log       bool
+	malformed atomic.Int64
+}
+
+// newCheckedPropagator creates a checkedPropagator around the given propagator
+func newCheckedPropagator(propagator propagation.TextMapPropagator, logger Logger, log bool) *checkedPropagator {
+	return &checkedPropagator{
+		TextMapPropagator: propagator,
+		logger:            logger,
+		log:               log,
+	}
+}
+
+// Extract extracts the trace context and records malformed trace headers
+func (p *checkedPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	before := trace.SpanContextFromContext(ctx)
+	extracted := p.TextMapPropagator.Extract(ctx, carrier)
+
+	if !trace.SpanContextFromContext(extracted).Equal(before) {
+		return extracted
+	}
+
+	field, value, ok := p.traceHeader(carrier)
+	if !ok {
+		return extracted
+	}
+
+	p.malformed.Add(1)
+	if p.log {
+		p.logger.Printf("goteletracer: malformed trace context in %q header %q, starting a new trace", field, value)
+	}
+
+	return context.WithValue(extracted, malformedContextKey{}, true)
+}
+
+// traceHeader returns the first non-empty trace header from the carrier
+func (p *checkedPropagator) traceHeader(carrier propagation.TextMapCarrier) (string, string, bool) {
+	for _, field := range p.Fields() {
+		if field == baggageField {
+			continue
+		}
+
+		if value := carrier.Get(field); value != "" {
+			return field, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+// IsMalformedContext reports whether ctx was produced by an extraction that found
+// trace headers but could not parse a valid span context from them
+func IsMalformedContext(ctx context.Context) bool {
+	malformed, _ := ctx.Value(malformedContextKey{}).(bool)
+	return malformed
+}
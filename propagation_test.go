@@ -0,0 +1,321 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// TestMetadataCarrier tests the TextMapCarrier implementation over gRPC metadata
+func TestMetadataCarrier(t *testing.T) {
+	md := metadata.MD{}
+	carrier := MetadataCarrier(md)
+
+	if got := carrier.Get("traceparent"); got != "" {
+		t.Errorf("expected empty value for missing key, got %q", got)
+	}
+
+	carrier.Set("Traceparent", "value-1")
+	carrier.Set("traceparent", "value-2")
+
+	if got := carrier.Get("TRACEPARENT"); got != "value-2" {
+		t.Errorf("expected case-insensitive lookup to return %q, got %q", "value-2", got)
+	}
+	if keys := carrier.Keys(); len(keys) != 1 || keys[0] != "traceparent" {
+		t.Errorf("expected a single lowercased key, got %v", keys)
+	}
+}
+
+// TestMetadataRoundTrip tests propagating a trace through gRPC metadata
+func TestMetadataRoundTrip(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	ctx, span := provider.Tracer().Start(context.Background(), "client")
+	defer span.End()
+
+	md := metadata.MD{}
+	InjectMetadata(ctx, md)
+
+	if len(md.Get("traceparent")) != 1 {
+		t.Fatalf("expected traceparent to be injected, got %v", md)
+	}
+
+	extracted := trace.SpanContextFromContext(ExtractMetadata(context.Background(), md))
+	if !extracted.IsRemote() {
+		t.Error("expected extracted span context to be remote")
+	}
+	if extracted.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("expected trace id %s, got %s", span.SpanContext().TraceID(), extracted.TraceID())
+	}
+	if extracted.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected span id %s, got %s", span.SpanContext().SpanID(), extracted.SpanID())
+	}
+}
+
+// TestCheckedPropagator tests counting and flagging of malformed trace headers
+func TestCheckedPropagator(t *testing.T) {
+	const validTraceparent = "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01"
+
+	tests := []struct {
+		name            string
+		headers         map[string]string
+		expectMalformed bool
+	}{
+		{name: "no headers", headers: map[string]string{}, expectMalformed: false},
+		{name: "valid traceparent", headers: map[string]string{"traceparent": validTraceparent}, expectMalformed: false},
+		{name: "baggage only", headers: map[string]string{"baggage": "tenant=acme"}, expectMalformed: false},
+		{name: "malformed traceparent", headers: map[string]string{"traceparent": "00-garbage"}, expectMalformed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &captureLogger{}
+			propagator := newCheckedPropagator(
+				propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+				logger,
+				true,
+			)
+
+			ctx := propagator.Extract(context.Background(), propagation.MapCarrier(tt.headers))
+
+			if IsMalformedContext(ctx) != tt.expectMalformed {
+				t.Errorf("expected malformed=%v, got %v", tt.expectMalformed, IsMalformedContext(ctx))
+			}
+
+			expectedCount := 0
+			if tt.expectMalformed {
+				expectedCount = 1
+			}
+			if got := propagator.malformed.Load(); got != int64(expectedCount) {
+				t.Errorf("expected malformed count %d, got %d", expectedCount, got)
+			}
+			if logger.count() != expectedCount {
+				t.Errorf("expected %d log messages, got %d", expectedCount, logger.count())
+			}
+		})
+	}
+}
+
+// TestMarkMalformedContext tests that spans from a malformed context are marked and counted
+func TestMarkMalformedContext(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:          "test-service",
+		ExporterGRPCAddress:  "localhost:4317",
+		MarkMalformedContext: true,
+	})
+
+	md := metadata.Pairs("traceparent", "not-a-traceparent")
+	ctx := ExtractMetadata(context.Background(), md)
+
+	_, span := provider.Tracer().Start(ctx, "server")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Parent.IsValid() {
+		t.Error("expected a new trace to be started")
+	}
+	if value, ok := spanAttribute(spans[0], MalformedParentContextKey); !ok || !value.AsBool() {
+		t.Errorf("expected malformed parent attribute, got %v", spans[0].Attributes)
+	}
+	if got := provider.Stats().MalformedContexts; got != 1 {
+		t.Errorf("expected 1 malformed context, got %d", got)
+	}
+}
+
+// TestNewPropagator tests building composite propagators from names
+func TestNewPropagator(t *testing.T) {
+	tests := []struct {
+		name           string
+		propagators    []string
+		expectedFields []string
+		expectedErr    error
+	}{
+		{
+			name:           "default",
+			propagators:    nil,
+			expectedFields: []string{"traceparent", "tracestate", "baggage"},
+		},
+		{
+			name:           "b3 and jaeger",
+			propagators:    []string{PropagatorB3, PropagatorJaeger},
+			expectedFields: []string{"x-b3-traceid", "uber-trace-id"},
+		},
+		{
+			name:        "unknown",
+			propagators: []string{PropagatorTraceContext, "xray"},
+			expectedErr: ErrUnknownPropagator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			propagator, err := newPropagator(tt.propagators)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			fields := propagator.Fields()
+			for _, field := range tt.expectedFields {
+				if !slices.Contains(fields, field) {
+					t.Errorf("expected field %q in %v", field, fields)
+				}
+			}
+		})
+	}
+}
+
+// TestConfigPropagators tests that the provider extracts contexts with the configured propagators
+func TestConfigPropagators(t *testing.T) {
+	provider, _ := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		Propagators:         []string{PropagatorB3},
+	})
+
+	carrier := propagation.MapCarrier{
+		"x-b3-traceid": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"x-b3-spanid":  "00f067aa0ba902b7",
+		"x-b3-sampled": "1",
+	}
+	ctx := provider.propagator.Extract(context.Background(), carrier)
+
+	if traceID := trace.SpanContextFromContext(ctx).TraceID().String(); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the B3 trace ID to be extracted, got %q", traceID)
+	}
+}
+
+// TestProviderPropagator tests that the provider keeps its configured propagators apart from the global one
+func TestProviderPropagator(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	tests := []struct {
+		name           string
+		propagators    []string
+		expectedFields []string
+	}{
+		{name: "default", expectedFields: []string{"traceparent", "tracestate", "baggage"}},
+		{name: "B3 and Jaeger", propagators: []string{PropagatorB3, PropagatorJaeger}, expectedFields: []string{"x-b3-traceid", "uber-trace-id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, _ := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				Propagators:         tt.propagators,
+				DisableGlobal:       true,
+			})
+
+			fields := provider.Propagator().Fields()
+			for _, expected := range tt.expectedFields {
+				if !slices.Contains(fields, expected) {
+					t.Errorf("expected propagator field %q, got %v", expected, fields)
+				}
+			}
+		})
+	}
+
+	if fields := otel.GetTextMapPropagator().Fields(); !slices.Equal(fields, []string{"traceparent", "tracestate"}) {
+		t.Errorf("expected the global propagator to be left untouched, got %v", fields)
+	}
+}
+
+// TestProviderInjectExtract tests round-tripping a trace through a carrier with the provider's propagators
+func TestProviderInjectExtract(t *testing.T) {
+	provider, _ := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		Propagators:         []string{PropagatorB3},
+		DisableGlobal:       true,
+	})
+
+	ctx, span := provider.Tracer().Start(context.Background(), "producer")
+	defer span.End()
+
+	carrier := propagation.MapCarrier{}
+	provider.Inject(ctx, carrier)
+
+	if carrier.Get("b3") == "" {
+		t.Fatalf("expected B3 headers to be injected, got %v", carrier)
+	}
+
+	extracted := trace.SpanContextFromContext(provider.Extract(context.Background(), carrier))
+	if !extracted.IsRemote() {
+		t.Error("expected extracted span context to be remote")
+	}
+	if extracted.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("expected trace id %s, got %s", span.SpanContext().TraceID(), extracted.TraceID())
+	}
+}
+
+// TestBaggageExcluded tests that baggage is neither injected nor extracted when only trace context is selected
+func TestBaggageExcluded(t *testing.T) {
+	tests := []struct {
+		name          string
+		propagators   []string
+		expectBaggage bool
+	}{
+		{name: "default", expectBaggage: true},
+		{name: "tracecontext only", propagators: []string{PropagatorTraceContext}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, _ := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				Propagators:         tt.propagators,
+				DisableGlobal:       true,
+			})
+
+			ctx, span := provider.Tracer().Start(SetBaggage(context.Background(), "tenant.id", "acme"), "operation")
+			defer span.End()
+
+			carrier := propagation.MapCarrier{}
+			provider.Inject(ctx, carrier)
+
+			if carrier.Get("traceparent") == "" {
+				t.Errorf("expected traceparent to be injected, got %v", carrier)
+			}
+			if _, ok := carrier["baggage"]; ok != tt.expectBaggage {
+				t.Errorf("expected baggage header=%v, got %v", tt.expectBaggage, carrier)
+			}
+
+			incoming := propagation.MapCarrier{"baggage": "tenant.id=acme"}
+			extracted := provider.Extract(context.Background(), incoming)
+			if got := GetBaggage(extracted, "tenant.id") != ""; got != tt.expectBaggage {
+				t.Errorf("expected baggage extracted=%v, got %v", tt.expectBaggage, got)
+			}
+		})
+	}
+}
+
+// TestConfigPropagatorsValidation tests that unknown propagator names are rejected
+func TestConfigPropagatorsValidation(t *testing.T) {
+	err := ValidateConfig(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		Propagators:         []string{"unknown"},
+	})
+
+	if !errors.Is(err, ErrUnknownPropagator) {
+		t.Errorf("expected %v, got %v", ErrUnknownPropagator, err)
+	}
+}
@@ -0,0 +1,113 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+)
+
+var (
+	// ErrReconnectUnsupported is returned by Reconnect for providers without a GRPC connection
+	ErrReconnectUnsupported = errors.New("provider has no GRPC connection to reconnect")
+	// ErrProviderShutdown is returned by Reconnect once the provider has been shut down
+	ErrProviderShutdown = errors.New("tracer provider is shut down")
+)
+
+// Reconnect replaces the GRPC connections and the OTLP exporters, including those of
+// ExporterAddresses, with new ones built from a copy of the configuration taken when the
+// provider was created, e.g. after the collector was redeployed or DNS changed. The dials
+// are bounded by ConnectTimeout. Tracers keep working throughout: exports in
+// flight finish on the old connections and later batches use the new ones. The old
+// exporters and connections are then released. When one of the collectors cannot be
+// dialed, no connection is replaced. Providers without a GRPC connection, such as those
+// using WriterExporter, return ErrReconnectUnsupported.
+func (tp *TracerProvider) Reconnect() error {
+	if tp.swappable == nil {
+		return ErrReconnectUnsupported
+	}
+
+	resolved := resolveConfig(tp.config)
+	ctx, cancel := context.WithTimeout(context.Background(), resolved.ConnectTimeout)
+	defer cancel()
+
+	addresses := append([]string{tp.config.ExporterGRPCAddress}, tp.config.ExporterAddresses...)
+	conns := make([]*grpc.ClientConn, 0, len(addresses))
+	exporters := make([]sdk_trace.SpanExporter, 0, len(addresses))
+	for _, address := range addresses {
+		grpcConn, tracerExporter, err := dialExporter(ctx, tp.config, address, tp.options)
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return fmt.Errorf("failed to reconnect: %w", err)
+		}
+
+		conns = append(conns, grpcConn)
+		exporters = append(exporters, tracerExporter)
+	}
+
+	tp.connMu.Lock()
+	if tp.closed {
+		tp.connMu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return ErrProviderShutdown
+	}
+	previousConns := append([]*grpc.ClientConn{tp.grpcConn}, tp.extraConns...)
+	previousExporters := []sdk_trace.SpanExporter{tp.swappable.swap(exporters[0])}
+	for i, swappable := range tp.extraSwappables {
+		previousExporters = append(previousExporters, swappable.swap(exporters[i+1]))
+	}
+	tp.grpcConn = conns[0]
+	tp.extraConns = conns[1:]
+	tp.connMu.Unlock()
+
+	var errs []error
+	for _, previousExporter := range previousExporters {
+		if err := previousExporter.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown previous exporter: %w", err))
+		}
+	}
+
+	for _, previousConn := range previousConns {
+		if err := previousConn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close previous GRPC connection: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// connection returns the current GRPC connection to the collector, or nil if there is none
+func (tp *TracerProvider) connection() *grpc.ClientConn {
+	tp.connMu.Lock()
+	defer tp.connMu.Unlock()
+
+	return tp.grpcConn
+}
+
+// connections returns the current GRPC connections to every collector, the primary one first
+func (tp *TracerProvider) connections() []*grpc.ClientConn {
+	tp.connMu.Lock()
+	defer tp.connMu.Unlock()
+
+	if tp.grpcConn == nil {
+		return nil
+	}
+
+	return append([]*grpc.ClientConn{tp.grpcConn}, tp.extraConns...)
+}
+
+// closeConnection marks the provider as shut down and returns the GRPC connections to close:
+// the primary one and those of ExporterAddresses
+func (tp *TracerProvider) closeConnection() (*grpc.ClientConn, []*grpc.ClientConn) {
+	tp.connMu.Lock()
+	defer tp.connMu.Unlock()
+
+	tp.closed = true
+	return tp.grpcConn, tp.extraConns
+}
@@ -0,0 +1,129 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// TestReconnect tests that Reconnect replaces the connection while spans keep being exported
+func TestReconnect(t *testing.T) {
+	collector := newTestCollector(t)
+
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: collector.address,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	tracer := provider.Tracer()
+	_, span := tracer.Start(context.Background(), "before reconnect")
+	span.End()
+
+	previousConn := provider.connection()
+	if err := provider.Reconnect(); err != nil {
+		t.Fatalf("expected no reconnect error, got %v", err)
+	}
+
+	if provider.connection() == previousConn {
+		t.Error("expected a new GRPC connection after reconnect")
+	}
+	if state := previousConn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("expected previous connection to be shut down, got %s", state)
+	}
+
+	_, span = tracer.Start(context.Background(), "after reconnect")
+	span.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if spans := collector.exportedSpans(); spans != 2 {
+		t.Errorf("expected 2 exported spans, got %d", spans)
+	}
+}
+
+// TestReconnectUsesConfigCopy tests that Reconnect ignores changes made to the caller's config after creation
+func TestReconnectUsesConfigCopy(t *testing.T) {
+	collector := newTestCollector(t)
+
+	cfg := &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: collector.address,
+		Headers:             map[string]string{"x-tenant": "a"},
+	}
+	provider, err := NewTracerProvider(cfg)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	cfg.ExporterGRPCAddress = closedAddress(t)
+	cfg.ExporterAddresses = append(cfg.ExporterAddresses, closedAddress(t))
+	cfg.Headers["x-tenant"] = "b"
+
+	if err := provider.Reconnect(); err != nil {
+		t.Fatalf("expected no reconnect error, got %v", err)
+	}
+	if target := provider.connection().Target(); target != collector.address {
+		t.Errorf("expected reconnect to dial %s, got %s", collector.address, target)
+	}
+	if len(provider.extraSwappables) != 0 {
+		t.Errorf("expected no extra exporters, got %d", len(provider.extraSwappables))
+	}
+	if header := provider.config.Headers["x-tenant"]; header != "a" {
+		t.Errorf("expected the copied header to stay a, got %s", header)
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "after reconnect")
+	span.End()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+	if spans := collector.exportedSpans(); spans != 1 {
+		t.Errorf("expected 1 exported span, got %d", spans)
+	}
+}
+
+// TestReconnectUnsupported tests Reconnect on providers that cannot reconnect
+func TestReconnectUnsupported(t *testing.T) {
+	t.Run("writer exporter", func(t *testing.T) {
+		provider, err := NewTracerProvider(&Config{
+			ServiceName:    "test-service",
+			WriterExporter: io.Discard,
+		})
+		if err != nil {
+			t.Fatalf("failed to create provider: %v", err)
+		}
+		defer provider.Shutdown(context.Background())
+
+		if err := provider.Reconnect(); !errors.Is(err, ErrReconnectUnsupported) {
+			t.Errorf("expected ErrReconnectUnsupported, got %v", err)
+		}
+	})
+
+	t.Run("shut down provider", func(t *testing.T) {
+		provider, err := NewTracerProvider(&Config{
+			ServiceName:         "test-service",
+			ExporterGRPCAddress: closedAddress(t),
+			ShutdownTimeout:     time.Second,
+		})
+		if err != nil {
+			t.Fatalf("failed to create provider: %v", err)
+		}
+		provider.Shutdown(context.Background())
+
+		if err := provider.Reconnect(); !errors.Is(err, ErrProviderShutdown) {
+			t.Errorf("expected ErrProviderShutdown, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package goteletracer
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// spanRecorder is a span processor retaining the most recently ended spans in a
+// fixed size ring buffer for debugging without a trace backend
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []sdk_trace.ReadOnlySpan
+	next  int
+	full  bool
+}
+
+// newSpanRecorder creates a spanRecorder retaining up to limit spans
+func newSpanRecorder(limit int) *spanRecorder {
+	return &spanRecorder{
+		spans: make([]sdk_trace.ReadOnlySpan, limit),
+	}
+}
+
+// OnStart does nothing
+func (r *spanRecorder) OnStart(parent context.Context, s sdk_trace.ReadWriteSpan) {}
+
+// OnEnd retains the span, evicting the oldest one when the buffer is full
+func (r *spanRecorder) OnEnd(s sdk_trace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.spans[r.next] = s
+	r.next = (r.next + 1) % len(r.spans)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Shutdown does nothing
+func (r *spanRecorder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush does nothing
+func (r *spanRecorder) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the retained spans from oldest to newest
+func (r *spanRecorder) Spans() []sdk_trace.ReadOnlySpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]sdk_trace.ReadOnlySpan(nil), r.spans[:r.next]...)
+	}
+
+	return append(append([]sdk_trace.ReadOnlySpan(nil), r.spans[r.next:]...), r.spans[:r.next]...)
+}
+
+// spanJSON is the JSON representation of a retained span
+type spanJSON struct {
+	Name              string         `json:"name"`
+	TraceID           string         `json:"trace_id"`
+	SpanID            string         `json:"span_id"`
+	ParentSpanID      string         `json:"parent_span_id,omitempty"`
+	Kind              string         `json:"kind"`
+	Attributes        map[string]any `json:"attributes,omitempty"`
+	Status            string         `json:"status"`
+	StatusDescription string         `json:"status_description,omitempty"`
+	StartTime         time.Time      `json:"start_time"`
+	EndTime           time.Time      `json:"end_time"`
+	Duration          string         `json:"duration"`
+}
+
+// newSpanJSON converts a span into its JSON representation
+func newSpanJSON(span sdk_trace.ReadOnlySpan) spanJSON {
+	result := spanJSON{
+		Name:              span.Name(),
+		TraceID:           span.SpanContext().TraceID().String(),
+		SpanID:            span.SpanContext().SpanID().String(),
+		Kind:              span.SpanKind().String(),
+		Status:            span.Status().Code.String(),
+		StatusDescription: span.Status().Description,
+		StartTime:         span.StartTime(),
+		EndTime:           span.EndTime(),
+		Duration:          span.EndTime().Sub(span.StartTime()).String(),
+	}
+
+	if parent := span.Parent(); parent.IsValid() {
+		result.ParentSpanID = parent.SpanID().String()
+	}
+
+	if attributes := span.Attributes(); len(attributes) > 0 {
+		result.Attributes = make(map[string]any, len(attributes))
+		for _, attr := range attributes {
+			result.Attributes[string(attr.Key)] = attr.Value.AsInterface()
+		}
+	}
+
+	return result
+}
+
+// SpansJSON serializes the spans retained through RetainSpans as a JSON array,
+// from oldest to newest, e.g. to inspect recent traces behind an admin route.
+// Returns ErrSpanRetentionDisabled if RetainSpans is not configured.
+func (tp *TracerProvider) SpansJSON() ([]byte, error) {
+	if tp.recorder == nil {
+		return nil, ErrSpanRetentionDisabled
+	}
+
+	spans := tp.recorder.Spans()
+	result := make([]spanJSON, 0, len(spans))
+	for _, span := range spans {
+		result = append(result, newSpanJSON(span))
+	}
+
+	return json.Marshal(result)
+}
@@ -0,0 +1,66 @@
+package goteletracer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TestSpansJSON tests serializing retained spans with a bounded buffer
+func TestSpansJSON(t *testing.T) {
+	provider, _ := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		RetainSpans:         2,
+	})
+
+	tracer := provider.Tracer()
+	_, evicted := tracer.Start(context.Background(), "evicted")
+	evicted.End()
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child")
+	child.SetAttributes(attribute.String("user.id", "42"))
+	child.SetStatus(codes.Error, "boom")
+	child.End()
+	parent.End()
+
+	data, err := provider.SpansJSON()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var spans []spanJSON
+	if err := json.Unmarshal(data, &spans); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 retained spans, got %d", len(spans))
+	}
+	if spans[0].Name != "child" || spans[1].Name != "parent" {
+		t.Errorf("expected spans ordered oldest first, got %q, %q", spans[0].Name, spans[1].Name)
+	}
+	if spans[0].ParentSpanID != spans[1].SpanID || spans[0].TraceID != spans[1].TraceID {
+		t.Error("expected the child to reference its parent")
+	}
+	if spans[0].Attributes["user.id"] != "42" {
+		t.Errorf("expected attributes to be serialized, got %v", spans[0].Attributes)
+	}
+	if spans[0].Status != "Error" || spans[0].StatusDescription != "boom" {
+		t.Errorf("expected error status, got %q %q", spans[0].Status, spans[0].StatusDescription)
+	}
+}
+
+// TestSpansJSONDisabled tests the error returned when retention is not configured
+func TestSpansJSONDisabled(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	if _, err := provider.SpansJSON(); !errors.Is(err, ErrSpanRetentionDisabled) {
+		t.Errorf("expected %v, got %v", ErrSpanRetentionDisabled, err)
+	}
+}
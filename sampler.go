@@ -0,0 +1,230 @@
+package goteletracer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanKindSampler applies a different sampling ratio per span kind.
+// Spans whose kind has no configured ratio are delegated to the fallback sampler.
+type spanKindSampler struct {
+	samplers map[trace.SpanKind]sdk_trace.Sampler
+	fallback sdk_trace.Sampler
+}
+
+// newSpanKindSampler creates a spanKindSampler from a ratio per span kind
+func newSpanKindSampler(ratios map[trace.SpanKind]float64, fallback sdk_trace.Sampler) *spanKindSampler {
+	samplers := make(map[trace.SpanKind]sdk_trace.Sampler, len(ratios))
+	for kind, ratio := range ratios {
+		samplers[kind] = sdk_trace.TraceIDRatioBased(ratio)
+	}
+
+	return &spanKindSampler{
+		samplers: samplers,
+		fallback: fallback,
+	}
+}
+
+// ShouldSample delegates the decision to the sampler configured for the span kind
+func (s *spanKindSampler) ShouldSample(params sdk_trace.SamplingParameters) sdk_trace.SamplingResult {
+	if sampler, ok := s.samplers[params.Kind]; ok {
+		return sampler.ShouldSample(params)
+	}
+
+	return s.fallback.ShouldSample(params)
+}
+
+// Description returns a stable description of the sampler
+func (s *spanKindSampler) Description() string {
+	kinds := make([]trace.SpanKind, 0, len(s.samplers))
+	for kind := range s.samplers {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+
+	parts := make([]string, 0, len(kinds)+1)
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s:%s", kind, s.samplers[kind].Description()))
+	}
+	parts = append(parts, fmt.Sprintf("default:%s", s.fallback.Description()))
+
+	return fmt.Sprintf("SpanKindSampler{%s}", strings.Join(parts, ","))
+}
+
+// scopeSamplingKey is the context key carrying the scopeSampling of the tracer starting a span
+type scopeSamplingKey struct{}
+
+// scopeSampling is the sampler configured for an instrumentation scope through ScopeSamplingRatios
+type scopeSampling struct {
+	ratio   float64
+	sampler sdk_trace.Sampler
+}
+
+// newScopeSampling creates a scopeSampling sampling at the given ratio
+func newScopeSampling(ratio float64) *scopeSampling {
+	return &scopeSampling{
+		ratio:   ratio,
+		sampler: sdk_trace.TraceIDRatioBased(ratio),
+	}
+}
+
+// scopeSamplingFromContext returns the scopeSampling stored by a scopeTracer, if any
+func scopeSamplingFromContext(ctx context.Context) (*scopeSampling, bool) {
+	sampling, ok := ctx.Value(scopeSamplingKey{}).(*scopeSampling)
+	return sampling, ok
+}
+
+// scopeTracer wraps a tracer whose scope has its own sampling ratio. The SDK does not pass
+// the instrumentation scope to samplers, so it is carried in the context given to Start.
+type scopeTracer struct {
+	trace.Tracer
+	sampling *scopeSampling
+}
+
+// Start starts the span with the scope sampling in its start context only, so spans
+// from other tracers started below it are not affected
+func (t *scopeTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	_, span := t.Tracer.Start(context.WithValue(ctx, scopeSamplingKey{}, t.sampling), name, opts...)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// scopeSampler consults the sampler of the instrumentation scope starting the span, if it
+// has one, and the fallback sampler otherwise
+type scopeSampler struct {
+	fallback sdk_trace.Sampler
+}
+
+// ShouldSample delegates the decision to the scope sampler or the fallback
+func (s scopeSampler) ShouldSample(params sdk_trace.SamplingParameters) sdk_trace.SamplingResult {
+	if sampling, ok := scopeSamplingFromContext(params.ParentContext); ok {
+		return sampling.sampler.ShouldSample(params)
+	}
+
+	return s.fallback.ShouldSample(params)
+}
+
+// Description returns the description of the fallback sampler
+func (s scopeSampler) Description() string {
+	return fmt.Sprintf("ScopeSampler{default:%s}", s.fallback.Description())
+}
+
+// SamplingProbabilityKey is the span attribute key holding the probability with which
+// a sampled root span was kept, e.g. 0.1 for a span kept by a 10% ratio
+const SamplingProbabilityKey = attribute.Key("sampling.probability")
+
+// probabilitySampler wraps a sampler and records the sampling probability on the
+// root spans it samples, so backends can reweight sampled data
+type probabilitySampler struct {
+	sampler     sdk_trace.Sampler
+	probability func(trace.SpanKind) float64
+}
+
+// newProbabilitySampler creates a probabilitySampler resolving probabilities per span kind.
+// Spans started by a tracer with a scope sampling ratio report that ratio instead.
+func newProbabilitySampler(sampler sdk_trace.Sampler, probability func(trace.SpanKind) float64) *probabilitySampler {
+	return &probabilitySampler{
+		sampler:     sampler,
+		probability: probability,
+	}
+}
+
+// ShouldSample delegates the decision and adds the probability to sampled root spans
+func (s *probabilitySampler) ShouldSample(params sdk_trace.SamplingParameters) sdk_trace.SamplingResult {
+	result := s.sampler.ShouldSample(params)
+	if result.Decision != sdk_trace.RecordAndSample {
+		return result
+	}
+
+	if trace.SpanContextFromContext(params.ParentContext).IsValid() {
+		return result
+	}
+
+	probability := s.probability(params.Kind)
+	if sampling, ok := scopeSamplingFromContext(params.ParentContext); ok {
+		probability = sampling.ratio
+	}
+
+	result.Attributes = append(result.Attributes, SamplingProbabilityKey.Float64(probability))
+
+	return result
+}
+
+// Description returns the description of the wrapped sampler
+func (s *probabilitySampler) Description() string {
+	return s.sampler.Description()
+}
+
+// recordingSampler records every span but only samples a ratio of trace IDs for export.
+// Spans it does not sample stay recording, so span processors such as the recorder
+// behind SpansJSON still see them.
+type recordingSampler struct {
+	ratio   float64
+	sampler sdk_trace.Sampler
+}
+
+// newRecordingSampler creates a recordingSampler exporting the given ratio of trace IDs
+func newRecordingSampler(ratio float64) recordingSampler {
+	return recordingSampler{
+		ratio:   ratio,
+		sampler: sdk_trace.TraceIDRatioBased(ratio),
+	}
+}
+
+// ShouldSample samples spans by trace ID ratio and records the others instead of dropping them
+func (s recordingSampler) ShouldSample(params sdk_trace.SamplingParameters) sdk_trace.SamplingResult {
+	result := s.sampler.ShouldSample(params)
+	if result.Decision == sdk_trace.Drop {
+		result.Decision = sdk_trace.RecordOnly
+	}
+
+	return result
+}
+
+// Description returns a stable description of the sampler
+func (s recordingSampler) Description() string {
+	return fmt.Sprintf("RecordingSampler{%g}", s.ratio)
+}
+
+// debugForcingSampler always samples spans whose parent context carries a debug baggage
+// entry set to "true" and delegates every other decision to the wrapped sampler
+type debugForcingSampler struct {
+	key     string
+	sampler sdk_trace.Sampler
+}
+
+// newDebugForcingSampler creates a debugForcingSampler checking the given baggage key
+func newDebugForcingSampler(key string, sampler sdk_trace.Sampler) debugForcingSampler {
+	return debugForcingSampler{
+		key:     key,
+		sampler: sampler,
+	}
+}
+
+// ShouldSample samples flagged spans and delegates the others to the wrapped sampler
+func (s debugForcingSampler) ShouldSample(params sdk_trace.SamplingParameters) sdk_trace.SamplingResult {
+	if baggage.FromContext(params.ParentContext).Member(s.key).Value() != "true" {
+		return s.sampler.ShouldSample(params)
+	}
+
+	return sdk_trace.SamplingResult{
+		Decision:   sdk_trace.RecordAndSample,
+		Tracestate: trace.SpanContextFromContext(params.ParentContext).TraceState(),
+	}
+}
+
+// Description returns a stable description of the sampler
+func (s debugForcingSampler) Description() string {
+	return fmt.Sprintf("DebugForcingSampler{%s,%s}", s.key, s.sampler.Description())
+}
+
+// validSamplingRatio reports whether the ratio is within [0, 1]
+func validSamplingRatio(ratio float64) bool {
+	return ratio >= 0 && ratio <= 1
+}
@@ -0,0 +1,438 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdk_trace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestSpanKindSampler tests that the sampling decision depends on the span kind
+func TestSpanKindSampler(t *testing.T) {
+	sampler := newSpanKindSampler(map[trace.SpanKind]float64{
+		trace.SpanKindServer:   1,
+		trace.SpanKindInternal: 0,
+	}, sdk_trace.AlwaysSample())
+
+	tests := []struct {
+		name     string
+		kind     trace.SpanKind
+		expected sdk_trace.SamplingDecision
+	}{
+		{name: "server spans fully sampled", kind: trace.SpanKindServer, expected: sdk_trace.RecordAndSample},
+		{name: "internal spans dropped", kind: trace.SpanKindInternal, expected: sdk_trace.Drop},
+		{name: "unconfigured kind uses fallback", kind: trace.SpanKindClient, expected: sdk_trace.RecordAndSample},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sampler.ShouldSample(sdk_trace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       trace.TraceID{0x01},
+				Name:          "span",
+				Kind:          tt.kind,
+			})
+
+			if result.Decision != tt.expected {
+				t.Errorf("expected decision %v, got %v", tt.expected, result.Decision)
+			}
+		})
+	}
+}
+
+// TestSpanKindSamplerDescription tests that the description is stable
+func TestSpanKindSamplerDescription(t *testing.T) {
+	sampler := newSpanKindSampler(map[trace.SpanKind]float64{
+		trace.SpanKindInternal: 0.5,
+		trace.SpanKindServer:   1,
+	}, sdk_trace.AlwaysSample())
+
+	expected := "SpanKindSampler{internal:TraceIDRatioBased{0.5},server:AlwaysOnSampler,default:AlwaysOnSampler}"
+	if got := sampler.Description(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+// TestProviderSpanKindSampling tests that the provider applies per-kind ratios
+func TestProviderSpanKindSampling(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:            "test-service",
+		ExporterGRPCAddress:    "localhost:4317",
+		SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindInternal: 0},
+	})
+
+	tracer := provider.Tracer()
+	_, server := tracer.Start(context.Background(), "server", trace.WithSpanKind(trace.SpanKindServer))
+	server.End()
+	_, internal := tracer.Start(context.Background(), "internal", trace.WithSpanKind(trace.SpanKindInternal))
+	internal.End()
+
+	if err := provider.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "server" {
+		t.Errorf("expected only the server span to be exported, got %v", spans)
+	}
+}
+
+// TestInheritParentSampling tests that child spans follow the root decision when enabled
+func TestInheritParentSampling(t *testing.T) {
+	tests := []struct {
+		name          string
+		inherit       bool
+		expectedSpans int
+	}{
+		{name: "children sampled independently", inherit: false, expectedSpans: 1},
+		{name: "children inherit root decision", inherit: true, expectedSpans: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:            "test-service",
+				ExporterGRPCAddress:    "localhost:4317",
+				SpanKindSamplingRatios: map[trace.SpanKind]float64{trace.SpanKindInternal: 0},
+				InheritParentSampling:  tt.inherit,
+			})
+
+			tracer := provider.Tracer()
+			ctx, root := tracer.Start(context.Background(), "root", trace.WithSpanKind(trace.SpanKindServer))
+			_, child := tracer.Start(ctx, "child", trace.WithSpanKind(trace.SpanKindInternal))
+			child.End()
+			root.End()
+
+			if err := provider.provider.ForceFlush(context.Background()); err != nil {
+				t.Fatalf("expected no flush error, got %v", err)
+			}
+
+			if spans := exporter.GetSpans(); len(spans) != tt.expectedSpans {
+				t.Errorf("expected %d exported spans, got %d", tt.expectedSpans, len(spans))
+			}
+		})
+	}
+}
+
+// TestRecordSamplingProbability tests that sampled root spans carry their sampling probability
+func TestRecordSamplingProbability(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:               "test-service",
+		ExporterGRPCAddress:       "localhost:4317",
+		SpanKindSamplingRatios:    map[trace.SpanKind]float64{trace.SpanKindServer: 1, trace.SpanKindInternal: 0},
+		InheritParentSampling:     true,
+		RecordSamplingProbability: true,
+	})
+
+	tracer := provider.Tracer()
+	ctx, server := tracer.Start(context.Background(), "server", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "child", trace.WithSpanKind(trace.SpanKindInternal))
+	child.End()
+	server.End()
+	_, client := tracer.Start(context.Background(), "client", trace.WithSpanKind(trace.SpanKindClient))
+	client.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+
+	for _, span := range spans {
+		value, ok := spanAttribute(span, SamplingProbabilityKey)
+		if span.Name == "child" {
+			if ok {
+				t.Errorf("expected no sampling probability on child span, got %v", value.AsFloat64())
+			}
+			continue
+		}
+		if !ok || value.AsFloat64() != 1 {
+			t.Errorf("expected sampling probability 1 on %q, got %v", span.Name, span.Attributes)
+		}
+	}
+}
+
+// TestProbabilitySamplerRatio tests that the probability reflects the configured ratio
+func TestProbabilitySamplerRatio(t *testing.T) {
+	kindSampler := newSpanKindSampler(map[trace.SpanKind]float64{trace.SpanKindServer: 0.5}, sdk_trace.AlwaysSample())
+	sampler := newProbabilitySampler(kindSampler, func(trace.SpanKind) float64 { return 0.5 })
+
+	for i := 1; i <= 64; i++ {
+		result := sampler.ShouldSample(sdk_trace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       trace.TraceID{byte(i * 4)},
+			Kind:          trace.SpanKindServer,
+		})
+		if result.Decision != sdk_trace.RecordAndSample {
+			if len(result.Attributes) != 0 {
+				t.Errorf("expected no attributes on dropped span, got %v", result.Attributes)
+			}
+			continue
+		}
+
+		if len(result.Attributes) != 1 || result.Attributes[0] != SamplingProbabilityKey.Float64(0.5) {
+			t.Errorf("expected sampling probability 0.5, got %v", result.Attributes)
+		}
+	}
+}
+
+// TestScopeSamplingRatios tests that named tracers with a scope ratio sample their own spans
+func TestScopeSamplingRatios(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:               "test-service",
+		ExporterGRPCAddress:       "localhost:4317",
+		SpanKindSamplingRatios:    map[trace.SpanKind]float64{trace.SpanKindClient: 0},
+		ScopeSamplingRatios:       map[string]float64{"github.com/acme/lib": 0, "github.com/acme/client": 1},
+		RecordSamplingProbability: true,
+	})
+
+	ctx, app := provider.Tracer().Start(context.Background(), "app")
+	_, lib := provider.NamedTracer("github.com/acme/lib").Start(ctx, "lib")
+	libCtx, _ := provider.NamedTracer("github.com/acme/lib").Start(context.Background(), "lib-root")
+	_, nested := provider.Tracer().Start(libCtx, "nested")
+	_, client := provider.NamedTracer("github.com/acme/client").Start(context.Background(), "client", trace.WithSpanKind(trace.SpanKindClient))
+	for _, span := range []trace.Span{client, nested, lib, app} {
+		span.End()
+	}
+
+	spans := flushSpans(t, provider, exporter)
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+		if span.Name == "client" {
+			if value, ok := spanAttribute(span, SamplingProbabilityKey); !ok || value.AsFloat64() != 1 {
+				t.Errorf("expected scope sampling probability on client span, got %v", span.Attributes)
+			}
+		}
+	}
+
+	expected := []string{"client", "nested", "app"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected spans %v, got %v", expected, names)
+	}
+}
+
+// TestScopeSamplingRatiosValidation tests that scope ratios outside [0, 1] are rejected
+func TestScopeSamplingRatiosValidation(t *testing.T) {
+	err := ValidateConfig(&Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		ScopeSamplingRatios: map[string]float64{"github.com/acme/lib": 2},
+	})
+
+	if !errors.Is(err, ErrInvalidSamplingRatio) {
+		t.Errorf("expected %v, got %v", ErrInvalidSamplingRatio, err)
+	}
+}
+
+// TestSamplingRatio tests that root spans are sampled at the configured ratio and children follow
+func TestSamplingRatio(t *testing.T) {
+	const roots = 200
+
+	tests := []struct {
+		name     string
+		ratio    float64
+		minRoots int
+		maxRoots int
+	}{
+		{name: "zero samples every span", ratio: 0, minRoots: roots, maxRoots: roots},
+		{name: "one samples every span", ratio: 1, minRoots: roots, maxRoots: roots},
+		{name: "half samples some spans", ratio: 0.5, minRoots: 1, maxRoots: roots - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       tt.ratio,
+			})
+
+			tracer := provider.Tracer()
+			for range roots {
+				ctx, root := tracer.Start(context.Background(), "root")
+				_, child := tracer.Start(ctx, "child")
+				child.End()
+				root.End()
+			}
+
+			counts := map[string]int{}
+			for _, span := range flushSpans(t, provider, exporter) {
+				counts[span.Name]++
+			}
+
+			if counts["root"] < tt.minRoots || counts["root"] > tt.maxRoots {
+				t.Errorf("expected between %d and %d sampled roots, got %d", tt.minRoots, tt.maxRoots, counts["root"])
+			}
+			if counts["child"] != counts["root"] {
+				t.Errorf("expected children to follow their root, got %d roots and %d children", counts["root"], counts["child"])
+			}
+		})
+	}
+}
+
+// TestRecordingSampler tests that trace IDs outside the ratio are recorded instead of dropped
+func TestRecordingSampler(t *testing.T) {
+	sampler := newRecordingSampler(0.5)
+
+	tests := []struct {
+		name     string
+		traceID  trace.TraceID
+		expected sdk_trace.SamplingDecision
+	}{
+		{name: "low trace id sampled", traceID: trace.TraceID{8: 0x00, 15: 0x01}, expected: sdk_trace.RecordAndSample},
+		{name: "trace id below the bound sampled", traceID: trace.TraceID{8: 0x7f, 9: 0xff}, expected: sdk_trace.RecordAndSample},
+		{name: "trace id above the bound recorded", traceID: trace.TraceID{8: 0x80, 15: 0x01}, expected: sdk_trace.RecordOnly},
+		{name: "high trace id recorded", traceID: trace.TraceID{8: 0xff, 15: 0xff}, expected: sdk_trace.RecordOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sampler.ShouldSample(sdk_trace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       tt.traceID,
+				Name:          "span",
+			})
+
+			if result.Decision != tt.expected {
+				t.Errorf("expected decision %v, got %v", tt.expected, result.Decision)
+			}
+		})
+	}
+
+	if got := sampler.Description(); got != "RecordingSampler{0.5}" {
+		t.Errorf("expected description %q, got %q", "RecordingSampler{0.5}", got)
+	}
+}
+
+// TestRecordAllSampleRatio tests that every span is recorded while only a ratio is exported
+func TestRecordAllSampleRatio(t *testing.T) {
+	const roots = 200
+
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:          "test-service",
+		ExporterGRPCAddress:  "localhost:4317",
+		RecordAllSampleRatio: 0.5,
+	})
+
+	tracer := provider.Tracer()
+	exported := 0
+	for range roots {
+		ctx, root := tracer.Start(context.Background(), "root")
+		_, child := tracer.Start(ctx, "child")
+
+		if !root.IsRecording() || !child.IsRecording() {
+			t.Fatal("expected every span to be recording")
+		}
+		if child.SpanContext().IsSampled() != root.SpanContext().IsSampled() {
+			t.Fatal("expected child to follow the export decision of its root")
+		}
+		if root.SpanContext().IsSampled() {
+			exported += 2
+		}
+
+		child.End()
+		root.End()
+	}
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != exported {
+		t.Errorf("expected %d exported spans, got %d", exported, len(spans))
+	}
+	if exported == 0 || exported == 2*roots {
+		t.Errorf("expected only some spans to be exported, got %d of %d", exported, 2*roots)
+	}
+}
+
+// errorSampler samples spans started with an error attribute and drops the others
+type errorSampler struct{}
+
+// ShouldSample samples spans carrying error=true
+func (errorSampler) ShouldSample(params sdk_trace.SamplingParameters) sdk_trace.SamplingResult {
+	decision := sdk_trace.Drop
+	if slices.Contains(params.Attributes, attribute.Bool("error", true)) {
+		decision = sdk_trace.RecordAndSample
+	}
+
+	return sdk_trace.SamplingResult{Decision: decision}
+}
+
+// Description returns the name of the sampler
+func (errorSampler) Description() string {
+	return "ErrorSampler"
+}
+
+// TestConfigSampler tests that a configured sampler replaces the ratio-based samplers
+func TestConfigSampler(t *testing.T) {
+	provider, exporter := newTestProvider(t, &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		SamplingRatio:       1,
+		Sampler:             errorSampler{},
+	})
+
+	_, span := provider.Tracer().Start(context.Background(), "failed", trace.WithAttributes(attribute.Bool("error", true)))
+	span.End()
+	_, span = provider.Tracer().Start(context.Background(), "succeeded")
+	span.End()
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 || spans[0].Name != "failed" {
+		t.Errorf("expected only the failed span to be sampled, got %v", spans)
+	}
+}
+
+// TestDebugForcingSampler tests that flagged spans are always sampled and others follow the ratio
+func TestDebugForcingSampler(t *testing.T) {
+	const roots = 200
+
+	debugMember, _ := baggage.NewMember("debug", "true")
+	offMember, _ := baggage.NewMember("debug", "false")
+
+	tests := []struct {
+		name     string
+		members  []baggage.Member
+		minRoots int
+		maxRoots int
+	}{
+		{name: "flag present forces sampling", members: []baggage.Member{debugMember}, minRoots: roots, maxRoots: roots},
+		{name: "flag absent uses the ratio", minRoots: 1, maxRoots: roots - 1},
+		{name: "flag not true uses the ratio", members: []baggage.Member{offMember}, minRoots: 1, maxRoots: roots - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider, err := newTracerProvider(context.Background(), &Config{
+				ServiceName:         "test-service",
+				ExporterGRPCAddress: "localhost:4317",
+				SamplingRatio:       0.5,
+			}, exporter, WithDebugForcingSampler("debug"))
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+			t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+			bag, err := baggage.New(tt.members...)
+			if err != nil {
+				t.Fatalf("failed to create baggage: %v", err)
+			}
+			ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+			for range roots {
+				_, span := provider.Tracer().Start(ctx, "root")
+				span.End()
+			}
+
+			sampled := len(flushSpans(t, provider, exporter))
+			if sampled < tt.minRoots || sampled > tt.maxRoots {
+				t.Errorf("expected between %d and %d sampled roots, got %d", tt.minRoots, tt.maxRoots, sampled)
+			}
+		})
+	}
+}
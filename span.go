@@ -0,0 +1,255 @@
+package goteletracer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// unknownSpanName is used when the caller of StartSpan cannot be resolved
+const unknownSpanName = "unknown"
+
+// ContextDoneEventName is the span event added by StartSpan when the incoming
+// context is already cancelled or past its deadline
+const ContextDoneEventName = "context.done"
+
+// StartSpan starts a span and returns a function that ends it.
+// The returned function should be deferred with a pointer to the caller's named
+// error: a non-nil error is recorded on the span with an error status, otherwise
+// the span gets an ok status, unless an error status was already set.
+// An empty name defaults to the caller's function name, which costs a
+// runtime.Caller lookup on that path only, skipped for noop tracers.
+// When ctx is already done, the span gets a context.done event and an error status
+// so traces show that the work began after an upstream cancellation or timeout.
+func StartSpan(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (context.Context, func(err *error)) {
+	// Noop spans discard their name, so skip the caller lookup
+	if name == "" && !IsNoopTracer(tracer) {
+		name = callerName(2)
+	}
+
+	ctx, status := startSpan(ctx, tracer, name, opts...)
+	return ctx, status.end
+}
+
+// startSpan starts a span for StartSpan and StartSpanWithTimeout and returns its status tracker
+func startSpan(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (context.Context, *spanStatus) {
+	ctx, span := tracer.Start(ctx, name, opts...)
+	status := &spanStatus{span: span}
+
+	if err := ctx.Err(); err != nil {
+		cause := context.Cause(ctx)
+		span.AddEvent(ContextDoneEventName, trace.WithAttributes(
+			attribute.String("error", cause.Error()),
+		))
+		status.fail(fmt.Sprintf("span started with done context: %s", cause))
+	}
+
+	return ctx, status
+}
+
+// spanStatus tracks whether an error status was set on a span, since an ok status
+// would otherwise override it when the span ends
+type spanStatus struct {
+	span   trace.Span
+	failed atomic.Bool
+}
+
+// fail sets an error status on the span
+func (s *spanStatus) fail(description string) {
+	s.failed.Store(true)
+	s.span.SetStatus(codes.Error, description)
+}
+
+// end records a non-nil error or sets an ok status, then ends the span
+func (s *spanStatus) end(err *error) {
+	if err != nil && *err != nil {
+		s.span.RecordError(*err)
+		s.fail((*err).Error())
+	} else if !s.failed.Load() {
+		s.span.SetStatus(codes.Ok, "")
+	}
+
+	s.span.End()
+}
+
+// SpanFromContextWithAttrs returns the span stored in ctx after setting the attributes on it.
+// Attributes are only set when the span is recording, so callers can pass request-scoped
+// values without checking for a noop span first.
+func SpanFromContextWithAttrs(ctx context.Context, attrs ...attribute.KeyValue) trace.Span {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.SetAttributes(attrs...)
+	}
+
+	return span
+}
+
+// AddAttributesToSpan sets the attributes on the span stored in ctx, if it is recording
+func AddAttributesToSpan(ctx context.Context, attrs ...attribute.KeyValue) {
+	SpanFromContextWithAttrs(ctx, attrs...)
+}
+
+// StartLinkedSpan starts a span like StartSpan, linked to the given span contexts, e.g.
+// the producers of the messages a consumer handles across an async boundary.
+// Links with an invalid span context are dropped by the SDK.
+func StartLinkedSpan(ctx context.Context, tracer trace.Tracer, name string, links ...trace.Link) (context.Context, func(err *error)) {
+	// Noop spans discard their name, so skip the caller lookup
+	if name == "" && !IsNoopTracer(tracer) {
+		name = callerName(2)
+	}
+
+	ctx, status := startSpan(ctx, tracer, name, trace.WithLinks(links...))
+	return ctx, status.end
+}
+
+// LinkFromContext returns a link to the span stored in ctx, to be passed to StartLinkedSpan
+// from another trace. The link has an invalid span context when ctx carries no span.
+func LinkFromContext(ctx context.Context, attrs ...attribute.KeyValue) trace.Link {
+	return trace.LinkFromContext(ctx, attrs...)
+}
+
+// TimedOutKey is the span attribute key set by StartSpanWithTimeout when a span
+// outlives its maximum duration
+const TimedOutKey = attribute.Key("timed_out")
+
+// StartSpanWithTimeout starts a span like StartSpan and flags it as a likely hang when
+// it is not ended within maxDuration, by setting the timed_out attribute and an error status.
+// The watchdog cannot end the span on the caller's behalf; spans are still ended by
+// calling the returned function, which also stops the watchdog.
+func StartSpanWithTimeout(ctx context.Context, tracer trace.Tracer, name string, maxDuration time.Duration, opts ...trace.SpanStartOption) (context.Context, func(err *error)) {
+	// Noop spans discard their name, so skip the caller lookup
+	if name == "" && !IsNoopTracer(tracer) {
+		name = callerName(2)
+	}
+
+	ctx, status := startSpan(ctx, tracer, name, opts...)
+	if IsNoopTracer(tracer) {
+		return ctx, status.end
+	}
+
+	watchdog := time.AfterFunc(maxDuration, func() {
+		status.span.SetAttributes(TimedOutKey.Bool(true))
+		status.fail(fmt.Sprintf("span exceeded max duration of %s", maxDuration))
+	})
+
+	return ctx, func(err *error) {
+		watchdog.Stop()
+		status.end(err)
+	}
+}
+
+// StartSpanWithDeadline starts a span like StartSpan and ends it on the caller's behalf when
+// it is not ended within maxDuration, with the timed_out attribute and an error status, so
+// operations that are never bounded still export their span. Calling the returned function
+// after that does nothing; calling it in time stops the watchdog, leaving nothing running.
+func StartSpanWithDeadline(ctx context.Context, tracer trace.Tracer, name string, maxDuration time.Duration, opts ...trace.SpanStartOption) (context.Context, func(err *error)) {
+	// Noop spans discard their name, so skip the caller lookup
+	if name == "" && !IsNoopTracer(tracer) {
+		name = callerName(2)
+	}
+
+	ctx, status := startSpan(ctx, tracer, name, opts...)
+	if IsNoopTracer(tracer) {
+		return ctx, status.end
+	}
+
+	// Whichever of the watchdog and the caller comes first ends the span
+	var ended atomic.Bool
+	watchdog := time.AfterFunc(maxDuration, func() {
+		if !ended.CompareAndSwap(false, true) {
+			return
+		}
+		status.span.SetAttributes(TimedOutKey.Bool(true))
+		status.fail(fmt.Sprintf("span exceeded deadline of %s", maxDuration))
+		status.span.End()
+	})
+
+	return ctx, func(err *error) {
+		watchdog.Stop()
+		if ended.CompareAndSwap(false, true) {
+			status.end(err)
+		}
+	}
+}
+
+// StartSpanWithCaller starts a span like StartSpan with the code.function, code.filepath and
+// code.lineno attributes of its caller, for richer debugging. Each span costs a runtime.Caller
+// lookup, skipped for noop tracers, so prefer StartSpan on hot paths.
+// Attributes passed in opts with the same keys take precedence.
+func StartSpanWithCaller(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (context.Context, func(err *error)) {
+	if !IsNoopTracer(tracer) {
+		function, attrs := callerAttributes(2)
+		if name == "" {
+			name = function
+		}
+		opts = append([]trace.SpanStartOption{trace.WithAttributes(attrs...)}, opts...)
+	}
+
+	ctx, status := startSpan(ctx, tracer, name, opts...)
+	return ctx, status.end
+}
+
+// callerName returns the function name of the caller skip frames above callerName,
+// without the package path, e.g. "goteletracer.TestStartSpan"
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return unknownSpanName
+	}
+
+	return funcName(pc)
+}
+
+// callerAttributes returns the function name and the code attributes of the caller
+// skip frames above callerAttributes
+func callerAttributes(skip int) (string, []attribute.KeyValue) {
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return unknownSpanName, nil
+	}
+
+	function := funcName(pc)
+	return function, []attribute.KeyValue{
+		semconv.CodeFunction(function),
+		semconv.CodeFilepath(file),
+		semconv.CodeLineNumber(line),
+	}
+}
+
+// funcName returns the name of the function containing pc without the package path
+func funcName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return unknownSpanName
+	}
+
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return name
+}
+
+// requestIDContextKey is the context key holding the external request ID
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the external request ID, such as an
+// ingress X-Request-ID. Spans started from the returned context get the ID as an attribute.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
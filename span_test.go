@@ -0,0 +1,400 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// flushSpans forces the provider to export and returns the captured spans
+func flushSpans(t *testing.T, provider *TracerProvider, exporter *tracetest.InMemoryExporter) tracetest.SpanStubs {
+	t.Helper()
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	return exporter.GetSpans()
+}
+
+// spanAttribute returns the value of the attribute with the given key on a span stub
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+
+	return attribute.Value{}, false
+}
+
+// namedOperation starts a span with an empty name for TestStartSpanDefaultName
+func namedOperation(provider *TracerProvider) (err error) {
+	_, end := StartSpan(context.Background(), provider.Tracer(), "")
+	defer end(&err)
+
+	return nil
+}
+
+// TestStartSpanDefaultName tests that an empty span name defaults to the caller
+func TestStartSpanDefaultName(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	if err := namedOperation(provider); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, end := StartSpan(context.Background(), provider.Tracer(), "explicit")
+	end(nil)
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name != "goteletracer.namedOperation" {
+		t.Errorf("expected caller name %q, got %q", "goteletracer.namedOperation", spans[0].Name)
+	}
+	if spans[1].Name != "explicit" {
+		t.Errorf("expected explicit name to be kept, got %q", spans[1].Name)
+	}
+}
+
+// TestStartSpanRecordsError tests that a non-nil error is recorded when the span ends
+func TestStartSpanRecordsError(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	err := errors.New("operation failed")
+	_, end := StartSpan(context.Background(), provider.Tracer(), "failing")
+	end(&err)
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "operation failed" {
+		t.Errorf("expected error status, got %+v", spans[0].Status)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "exception" {
+		t.Errorf("expected an exception event, got %v", spans[0].Events)
+	}
+}
+
+// TestStartSpanOkStatus tests that spans ended with a nil error get an ok status
+func TestStartSpanOkStatus(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	var err error
+	_, end := StartSpan(context.Background(), provider.Tracer(), "succeeding")
+	end(&err)
+
+	_, end = StartSpan(context.Background(), provider.Tracer(), "nil pointer")
+	end(nil)
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if span.Status.Code != codes.Ok {
+			t.Errorf("%s: expected ok status, got %+v", span.Name, span.Status)
+		}
+		if len(span.Events) != 0 {
+			t.Errorf("%s: expected no events, got %v", span.Name, span.Events)
+		}
+	}
+}
+
+// TestStartSpanDoneContext tests that spans started from a done context are flagged
+func TestStartSpanDoneContext(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, end := StartSpan(ctx, provider.Tracer(), "late")
+	end(nil)
+
+	_, end = StartSpan(context.Background(), provider.Tracer(), "live")
+	end(nil)
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	late := spans[0]
+	if late.Status.Code != codes.Error || late.Status.Description == "" {
+		t.Errorf("expected error status, got %v", late.Status.Code)
+	}
+	if len(late.Events) != 1 || late.Events[0].Name != ContextDoneEventName {
+		t.Errorf("expected a %q event, got %v", ContextDoneEventName, late.Events)
+	}
+
+	live := spans[1]
+	if live.Status.Code != codes.Ok || len(live.Events) != 0 {
+		t.Errorf("expected live span to be unflagged, got status %v and %d events", live.Status.Code, len(live.Events))
+	}
+}
+
+// TestAddAttributesToSpan tests setting attributes on recording and noop spans from a context
+func TestAddAttributesToSpan(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	ctx, span := provider.Tracer().Start(context.Background(), "handler")
+	AddAttributesToSpan(ctx, attribute.String("user.id", "user-1"))
+	if got := SpanFromContextWithAttrs(ctx, attribute.Int("items", 3)); got != span {
+		t.Error("expected the span stored in the context")
+	}
+	span.End()
+
+	noopCtx, _ := noop.NewTracerProvider().Tracer("").Start(context.Background(), "noop")
+	AddAttributesToSpan(noopCtx, attribute.String("user.id", "user-2"))
+	if got := SpanFromContextWithAttrs(noopCtx, attribute.Int("items", 1)); got.IsRecording() {
+		t.Error("expected the non-recording span stored in the context")
+	}
+	AddAttributesToSpan(context.Background(), attribute.String("user.id", "user-3"))
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if value, _ := spanAttribute(spans[0], "user.id"); value.AsString() != "user-1" {
+		t.Errorf("expected user.id attribute, got %v", spans[0].Attributes)
+	}
+	if value, _ := spanAttribute(spans[0], "items"); value.AsInt64() != 3 {
+		t.Errorf("expected items attribute, got %v", spans[0].Attributes)
+	}
+}
+
+// TestRequestID tests that spans started from a request ID context carry the ID
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		expectedKey attribute.Key
+	}{
+		{name: "default key", key: "", expectedKey: DefaultRequestIDKey},
+		{name: "custom key", key: "http.request_id", expectedKey: "http.request_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, &Config{
+				ServiceName:           "test-service",
+				ExporterGRPCAddress:   "localhost:4317",
+				RequestIDAttributeKey: tt.key,
+			})
+
+			ctx := WithRequestID(context.Background(), "req-123")
+			if got := RequestIDFromContext(ctx); got != "req-123" {
+				t.Fatalf("expected request id %q, got %q", "req-123", got)
+			}
+
+			ctx, parent := provider.Tracer().Start(ctx, "parent")
+			_, child := provider.Tracer().Start(ctx, "child")
+			child.End()
+			parent.End()
+			_, untagged := provider.Tracer().Start(context.Background(), "untagged")
+			untagged.End()
+
+			for _, span := range flushSpans(t, provider, exporter) {
+				value, ok := spanAttribute(span, tt.expectedKey)
+				if span.Name == "untagged" {
+					if ok {
+						t.Errorf("expected no request id on %q", span.Name)
+					}
+					continue
+				}
+				if !ok || value.AsString() != "req-123" {
+					t.Errorf("expected request id on %q, got %v", span.Name, span.Attributes)
+				}
+			}
+		})
+	}
+}
+
+// TestStartSpanWithTimeout tests that spans outliving their max duration are flagged
+func TestStartSpanWithTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxDuration    time.Duration
+		work           time.Duration
+		expectTimedOut bool
+	}{
+		{name: "ends in time", maxDuration: time.Second, work: 0, expectTimedOut: false},
+		{name: "outlives max duration", maxDuration: 5 * time.Millisecond, work: 50 * time.Millisecond, expectTimedOut: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, exporter := newTestProvider(t, nil)
+
+			_, end := StartSpanWithTimeout(context.Background(), provider.Tracer(), "watched", tt.maxDuration)
+			time.Sleep(tt.work)
+			end(nil)
+
+			spans := flushSpans(t, provider, exporter)
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+
+			value, ok := spanAttribute(spans[0], TimedOutKey)
+			if ok != tt.expectTimedOut || (ok && !value.AsBool()) {
+				t.Errorf("expected timed_out=%v, got %v", tt.expectTimedOut, spans[0].Attributes)
+			}
+			expectedCode := codes.Ok
+			if tt.expectTimedOut {
+				expectedCode = codes.Error
+			}
+			if spans[0].Status.Code != expectedCode {
+				t.Errorf("expected %v status, got %+v", expectedCode, spans[0].Status)
+			}
+		})
+	}
+}
+
+// TestStartLinkedSpan tests that links built from other span contexts appear on the span
+func TestStartLinkedSpan(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+	tracer := provider.Tracer()
+
+	producerCtx, producer := tracer.Start(context.Background(), "producer")
+	producer.End()
+	otherCtx, other := tracer.Start(context.Background(), "other")
+	other.End()
+
+	_, end := StartLinkedSpan(context.Background(), tracer, "consumer",
+		LinkFromContext(producerCtx, attribute.String("messaging.operation", "process")),
+		LinkFromContext(otherCtx),
+	)
+	end(nil)
+
+	var consumer tracetest.SpanStub
+	for _, span := range flushSpans(t, provider, exporter) {
+		if span.Name == "consumer" {
+			consumer = span
+		}
+	}
+
+	if len(consumer.Links) != 2 {
+		t.Fatalf("expected 2 links, got %v", consumer.Links)
+	}
+	if consumer.Links[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("expected a link to the producer span, got %s", consumer.Links[0].SpanContext.SpanID())
+	}
+	if len(consumer.Links[0].Attributes) != 1 || consumer.Links[0].Attributes[0].Value.AsString() != "process" {
+		t.Errorf("expected the link attributes to be kept, got %v", consumer.Links[0].Attributes)
+	}
+	if consumer.Links[1].SpanContext.TraceID() != other.SpanContext().TraceID() {
+		t.Errorf("expected a link to the other trace, got %s", consumer.Links[1].SpanContext.TraceID())
+	}
+	if consumer.Parent.IsValid() {
+		t.Errorf("expected linked span to start a new trace, got parent %s", consumer.Parent.SpanID())
+	}
+}
+
+// TestLinkFromContextWithoutSpan tests that a context without a span yields an invalid link
+func TestLinkFromContextWithoutSpan(t *testing.T) {
+	if link := LinkFromContext(context.Background()); link.SpanContext.IsValid() {
+		t.Errorf("expected an invalid link, got %v", link.SpanContext)
+	}
+}
+
+// TestStartSpanWithDeadline tests that spans outliving their deadline are ended by the watchdog
+// and that spans ended in time are left alone
+func TestStartSpanWithDeadline(t *testing.T) {
+	t.Run("outlives deadline", func(t *testing.T) {
+		provider, exporter := newTestProvider(t, nil)
+
+		_, end := StartSpanWithDeadline(context.Background(), provider.Tracer(), "forgotten", 5*time.Millisecond)
+
+		deadline := time.Now().Add(time.Second)
+		for len(flushSpans(t, provider, exporter)) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		// Ending the span after the watchdog did must not change it
+		err := errors.New("late failure")
+		end(&err)
+
+		spans := flushSpans(t, provider, exporter)
+		if len(spans) != 1 {
+			t.Fatalf("expected the watchdog to end the span, got %d spans", len(spans))
+		}
+		if value, ok := spanAttribute(spans[0], TimedOutKey); !ok || !value.AsBool() {
+			t.Errorf("expected timed_out=true, got %v", spans[0].Attributes)
+		}
+		if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "span exceeded deadline of 5ms" {
+			t.Errorf("expected a deadline error status, got %+v", spans[0].Status)
+		}
+		if len(spans[0].Events) != 0 {
+			t.Errorf("expected the late error not to be recorded, got %v", spans[0].Events)
+		}
+	})
+
+	t.Run("ends in time", func(t *testing.T) {
+		provider, exporter := newTestProvider(t, nil)
+
+		_, end := StartSpanWithDeadline(context.Background(), provider.Tracer(), "bounded", 20*time.Millisecond)
+		end(nil)
+
+		// Wait past the deadline to catch a watchdog that was not stopped
+		time.Sleep(50 * time.Millisecond)
+
+		spans := flushSpans(t, provider, exporter)
+		if len(spans) != 1 {
+			t.Fatalf("expected 1 span, got %d", len(spans))
+		}
+		if _, ok := spanAttribute(spans[0], TimedOutKey); ok {
+			t.Errorf("expected no timed_out attribute, got %v", spans[0].Attributes)
+		}
+		if spans[0].Status.Code != codes.Ok {
+			t.Errorf("expected ok status, got %+v", spans[0].Status)
+		}
+	})
+}
+
+// TestStartSpanWithCaller tests that the code attributes reference the calling test
+func TestStartSpanWithCaller(t *testing.T) {
+	provider, exporter := newTestProvider(t, nil)
+
+	_, end := StartSpanWithCaller(context.Background(), provider.Tracer(), "")
+	_, file, line, _ := runtime.Caller(0)
+	end(nil)
+
+	_, end = StartSpanWithCaller(context.Background(), provider.Tracer(), "explicit", trace.WithAttributes(semconv.CodeFunction("override")))
+	end(nil)
+
+	spans := flushSpans(t, provider, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	const function = "goteletracer.TestStartSpanWithCaller"
+	if spans[0].Name != function {
+		t.Errorf("expected caller name %q, got %q", function, spans[0].Name)
+	}
+	if value, _ := spanAttribute(spans[0], semconv.CodeFunctionKey); value.AsString() != function {
+		t.Errorf("expected code.function %q, got %q", function, value.AsString())
+	}
+	if value, _ := spanAttribute(spans[0], semconv.CodeFilepathKey); value.AsString() != file || filepath.Base(value.AsString()) != "span_test.go" {
+		t.Errorf("expected code.filepath %q, got %q", file, value.AsString())
+	}
+	if value, _ := spanAttribute(spans[0], semconv.CodeLineNumberKey); value.AsInt64() != int64(line-1) {
+		t.Errorf("expected code.lineno %d, got %d", line-1, value.AsInt64())
+	}
+
+	if value, _ := spanAttribute(spans[1], semconv.CodeFunctionKey); value.AsString() != "override" {
+		t.Errorf("expected code.function from opts to take precedence, got %q", value.AsString())
+	}
+}
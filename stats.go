@@ -0,0 +1,63 @@
+package goteletracer
+
+// Stats holds export counters collected by the provider
+type Stats struct {
+	// Exported is the number of spans the exporter exported successfully
+	Exported int64
+	// Dropped is the number of spans the exporter failed to export; those then routed
+	// to FallbackExporter are also counted in FallbackExported
+	Dropped int64
+	// FallbackExported is the number of spans exported through FallbackExporter
+	// after the primary exporter failed
+	FallbackExported int64
+	// ExportLimitDropped is the number of spans dropped because MaxInFlightExports
+	// was reached while DropOnExportLimit is set
+	ExportLimitDropped int64
+	// InvalidLinkAttributes is the number of LinkAttributeKeys pairs skipped because
+	// they did not hold valid hex trace and span IDs
+	InvalidLinkAttributes int64
+	// MalformedContexts is the number of extractions where incoming trace headers
+	// were present but could not be parsed
+	MalformedContexts int64
+	// SpanNameViolations is the number of spans started with a name rejected by SpanNameValidator
+	SpanNameViolations int64
+	// QueueDepth estimates the number of ended spans waiting in the export queue, capped
+	// at MaxQueueSize. It is only tracked with TrackQueueDepth and always zero otherwise.
+	QueueDepth int64
+}
+
+// Stats returns a snapshot of the provider's export counters
+func (tp *TracerProvider) Stats() Stats {
+	var stats Stats
+
+	if tp.counter != nil {
+		stats.Exported = tp.counter.exported.Load()
+		stats.Dropped = tp.counter.dropped.Load()
+	}
+
+	if tp.fallback != nil {
+		stats.FallbackExported = tp.fallback.exported.Load()
+	}
+
+	if tp.limiter != nil {
+		stats.ExportLimitDropped = tp.limiter.dropped.Load()
+	}
+
+	if tp.linker != nil {
+		stats.InvalidLinkAttributes = tp.linker.invalid.Load()
+	}
+
+	if tp.propagator != nil {
+		stats.MalformedContexts = tp.propagator.malformed.Load()
+	}
+
+	if tp.nameConvention != nil {
+		stats.SpanNameViolations = tp.nameConvention.violations.Load()
+	}
+
+	for _, queue := range tp.queues {
+		stats.QueueDepth += queue.depth()
+	}
+
+	return stats
+}
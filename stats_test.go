@@ -0,0 +1,91 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestStatsFallbackExported tests that spans routed to the fallback exporter are counted
+func TestStatsFallbackExported(t *testing.T) {
+	fallback := tracetest.NewInMemoryExporter()
+	cfg := &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+		FallbackExporter:    fallback,
+	}
+
+	primary := &stubExporter{errs: []error{errors.New("collector unavailable")}}
+	provider, err := newTracerProvider(context.Background(), cfg, primary)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	_, span := provider.Tracer().Start(context.Background(), "fallback-span")
+	span.End()
+
+	if err := provider.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("expected no flush error, got %v", err)
+	}
+
+	if got := provider.Stats().FallbackExported; got != 1 {
+		t.Errorf("expected 1 fallback export, got %d", got)
+	}
+	if spans := fallback.GetSpans(); len(spans) != 1 || spans[0].Name != "fallback-span" {
+		t.Errorf("expected fallback exporter to receive the span, got %v", spans)
+	}
+}
+
+// TestStatsExportedAndDropped tests that exported and failed spans are counted
+func TestStatsExportedAndDropped(t *testing.T) {
+	exporter := &stubExporter{errs: []error{nil, errors.New("collector unavailable"), nil}}
+	provider, err := newTracerProvider(context.Background(), &Config{
+		ServiceName:         "test-service",
+		ExporterGRPCAddress: "localhost:4317",
+	}, exporter)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	// Each flush exports one batch, the second one fails
+	for _, spans := range []int{2, 3, 1} {
+		for range spans {
+			_, span := provider.Tracer().Start(context.Background(), "operation")
+			span.End()
+		}
+		provider.ForceFlush(context.Background())
+	}
+
+	stats := provider.Stats()
+	if stats.Exported != 3 {
+		t.Errorf("expected 3 exported spans, got %d", stats.Exported)
+	}
+	if stats.Dropped != 3 {
+		t.Errorf("expected 3 dropped spans, got %d", stats.Dropped)
+	}
+}
+
+// TestStatsWithoutWrappers tests that Stats is zero when no counters are configured
+func TestStatsWithoutWrappers(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	if stats := provider.Stats(); stats != (Stats{}) {
+		t.Errorf("expected zero stats, got %+v", stats)
+	}
+}
+
+// TestStatsExportLimitDropped tests that Stats reports spans dropped by the export limit
+func TestStatsExportLimitDropped(t *testing.T) {
+	provider := &TracerProvider{
+		limiter: newConcurrencyLimitExporter(&stubExporter{}, 1, true),
+	}
+	provider.limiter.dropped.Add(3)
+
+	if got := provider.Stats().ExportLimitDropped; got != 3 {
+		t.Errorf("expected 3 dropped spans, got %d", got)
+	}
+}
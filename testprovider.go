@@ -0,0 +1,51 @@
+package goteletracer
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewTestTracerProvider creates a TracerProvider that records spans in memory instead of
+// exporting them, so unit tests can assert on the spans emitted by instrumented code.
+// A nil config defaults to the "test-service" service name; ExporterGRPCAddress is not used.
+func NewTestTracerProvider(cfg *Config) (*TracerProvider, error) {
+	if cfg == nil {
+		cfg = &Config{ServiceName: "test-service"}
+	}
+
+	if err := validateConfigFields(cfg, false); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	memory := tracetest.NewInMemoryExporter()
+	tp, err := newTracerProvider(context.Background(), cfg, memory)
+	if err != nil {
+		return nil, err
+	}
+
+	tp.memory = memory
+
+	return tp, nil
+}
+
+// RecordedSpans flushes and returns the spans recorded so far by a provider created with
+// NewTestTracerProvider, in the order they ended. It returns nil for other providers.
+// Recorded spans are discarded on Shutdown, so read them before shutting down.
+func (tp *TracerProvider) RecordedSpans() tracetest.SpanStubs {
+	if tp.memory == nil {
+		return nil
+	}
+
+	tp.provider.ForceFlush(context.Background())
+
+	return tp.memory.GetSpans()
+}
+
+// ResetRecordedSpans discards the spans recorded by a provider created with NewTestTracerProvider
+func (tp *TracerProvider) ResetRecordedSpans() {
+	if tp.memory != nil {
+		tp.memory.Reset()
+	}
+}
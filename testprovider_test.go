@@ -0,0 +1,74 @@
+package goteletracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TestNewTestTracerProvider tests recording and resetting spans in memory
+func TestNewTestTracerProvider(t *testing.T) {
+	provider, err := NewTestTracerProvider(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	_, span := provider.Tracer().Start(context.Background(), "operation")
+	span.SetAttributes(attribute.String("user.id", "42"))
+	span.End()
+
+	spans := provider.RecordedSpans()
+	if len(spans) != 1 || spans[0].Name != "operation" {
+		t.Fatalf("expected the operation span, got %v", spans)
+	}
+	if value, ok := spanAttribute(spans[0], "user.id"); !ok || value.AsString() != "42" {
+		t.Errorf("expected user.id attribute, got %v", spans[0].Attributes)
+	}
+
+	provider.ResetRecordedSpans()
+	if spans := provider.RecordedSpans(); len(spans) != 0 {
+		t.Errorf("expected no spans after reset, got %d", len(spans))
+	}
+}
+
+// TestNewTestTracerProviderInvalidConfig tests that configs are validated without an exporter address
+func TestNewTestTracerProviderInvalidConfig(t *testing.T) {
+	if _, err := NewTestTracerProvider(&Config{}); !errors.Is(err, ErrEmptyServiceName) {
+		t.Errorf("expected %v, got %v", ErrEmptyServiceName, err)
+	}
+}
+
+// TestRecordedSpansWithoutMemory tests that regular providers record nothing
+func TestRecordedSpansWithoutMemory(t *testing.T) {
+	provider, _ := newTestProvider(t, nil)
+
+	if spans := provider.RecordedSpans(); spans != nil {
+		t.Errorf("expected nil, got %v", spans)
+	}
+}
+
+// ExampleNewTestTracerProvider shows asserting on the spans emitted by instrumented code
+func ExampleNewTestTracerProvider() {
+	provider, err := NewTestTracerProvider(&Config{ServiceName: "orders"})
+	if err != nil {
+		panic(err)
+	}
+	defer provider.Shutdown(context.Background())
+
+	placeOrder := func(ctx context.Context) (err error) {
+		_, end := StartSpan(ctx, provider.Tracer(), "place-order")
+		defer end(&err)
+
+		return errors.New("out of stock")
+	}
+	placeOrder(context.Background())
+
+	for _, span := range provider.RecordedSpans() {
+		fmt.Println(span.Name, span.Status.Code, span.Status.Description)
+	}
+	// Output: place-order Error out of stock
+}
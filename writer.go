@@ -0,0 +1,72 @@
+package goteletracer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	collector_trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// flusher is implemented by buffered writers such as *bufio.Writer
+type flusher interface {
+	Flush() error
+}
+
+// writerClient is an otlptrace.Client that writes length-delimited OTLP protobuf
+// ExportTraceServiceRequest messages to an io.Writer. Each message is prefixed
+// with its size as a varint, readable with protodelim.UnmarshalFrom.
+type writerClient struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// newWriterExporter creates an OTLP exporter that writes spans to the writer
+func newWriterExporter(ctx context.Context, writer io.Writer) (*otlptrace.Exporter, error) {
+	return otlptrace.New(ctx, &writerClient{writer: writer})
+}
+
+// Start does nothing as the writer is already open
+func (c *writerClient) Start(ctx context.Context) error {
+	return nil
+}
+
+// UploadTraces writes the spans as a single length-delimited export request
+func (c *writerClient) UploadTraces(ctx context.Context, protoSpans []*tracepb.ResourceSpans) error {
+	request := &collector_trace.ExportTraceServiceRequest{
+		ResourceSpans: protoSpans,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := protodelim.MarshalTo(c.writer, request); err != nil {
+		return fmt.Errorf("failed to write export request: %w", err)
+	}
+
+	return nil
+}
+
+// Stop flushes buffered writers and closes the writer if it is an io.Closer
+func (c *writerClient) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if f, ok := c.writer.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("failed to flush writer: %w", err)
+		}
+	}
+
+	if closer, ok := c.writer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close writer: %w", err)
+		}
+	}
+
+	return nil
+}
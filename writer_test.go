@@ -0,0 +1,113 @@
+package goteletracer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	collector_trace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// closeRecorder is an io.WriteCloser that records whether it was closed
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+// Close marks the writer as closed
+func (w *closeRecorder) Close() error {
+	w.closed = true
+	return nil
+}
+
+// readExportRequests decodes all length-delimited export requests from the reader
+func readExportRequests(t *testing.T, r io.Reader) []*collector_trace.ExportTraceServiceRequest {
+	t.Helper()
+
+	var requests []*collector_trace.ExportTraceServiceRequest
+	reader := bufio.NewReader(r)
+	for {
+		request := &collector_trace.ExportTraceServiceRequest{}
+		err := protodelim.UnmarshalFrom(reader, request)
+		if errors.Is(err, io.EOF) {
+			return requests
+		}
+		if err != nil {
+			t.Fatalf("failed to read export request: %v", err)
+		}
+		requests = append(requests, request)
+	}
+}
+
+// TestWriterExporter tests that spans are written as OTLP protobuf and read back
+func TestWriterExporter(t *testing.T) {
+	writer := &closeRecorder{}
+	provider, err := NewTracerProvider(&Config{
+		ServiceName:    "writer-service",
+		WriterExporter: writer,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, span := provider.Tracer().Start(context.Background(), "written-span")
+	span.End()
+
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected no shutdown error, got %v", err)
+	}
+
+	if !writer.closed {
+		t.Error("expected the writer to be closed on shutdown")
+	}
+
+	requests := readExportRequests(t, &writer.Buffer)
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 export request, got %d", len(requests))
+	}
+
+	resourceSpans := requests[0].GetResourceSpans()
+	if len(resourceSpans) != 1 || len(resourceSpans[0].GetScopeSpans()) != 1 {
+		t.Fatalf("unexpected resource spans: %v", resourceSpans)
+	}
+
+	spans := resourceSpans[0].GetScopeSpans()[0].GetSpans()
+	if len(spans) != 1 || spans[0].GetName() != "written-span" {
+		t.Errorf("expected the written span, got %v", spans)
+	}
+
+	var serviceName string
+	for _, attr := range resourceSpans[0].GetResource().GetAttributes() {
+		if attr.GetKey() == "service.name" {
+			serviceName = attr.GetValue().GetStringValue()
+		}
+	}
+	if serviceName != "writer-service" {
+		t.Errorf("expected service name %q, got %q", "writer-service", serviceName)
+	}
+}
+
+// TestWriterExporterFlushesBufferedWriter tests that buffered writers are flushed on stop
+func TestWriterExporterFlushesBufferedWriter(t *testing.T) {
+	var output bytes.Buffer
+	buffered := bufio.NewWriter(&output)
+
+	client := &writerClient{writer: buffered}
+	if err := client.UploadTraces(context.Background(), nil); err != nil {
+		t.Fatalf("expected no upload error, got %v", err)
+	}
+	if output.Len() != 0 {
+		t.Fatal("expected output to stay buffered before stop")
+	}
+
+	if err := client.Stop(context.Background()); err != nil {
+		t.Fatalf("expected no stop error, got %v", err)
+	}
+	if output.Len() == 0 {
+		t.Error("expected buffered output to be flushed on stop")
+	}
+}